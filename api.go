@@ -54,12 +54,14 @@ misrepresented as being the original software.
 // forget what it is supposed to mean, after all the "official" name is more of a joke than anything...
 package axis2
 
-// Needed for a commented out debugging function
-//import "strings"
-//import "fmt"
-
+import "errors"
 import "io"
 import "io/ioutil"
+import "os"
+import "strings"
+import "sync"
+import "sync/atomic"
+import "time"
 
 // DataSource is any item that implements either File or Dir (or, more rarely, both).
 // 
@@ -115,9 +117,104 @@ type File interface {
 	Size() int64
 }
 
+// RandomReader is an open File that supports random access.
+type RandomReader interface {
+	io.Reader
+	io.ReaderAt
+	io.Seeker
+	io.Closer
+}
+
+// RandomAccess may be implemented by Files that support random access reads.
+type RandomAccess interface {
+	// OpenRandom opens the File for random access reading. If random access is not possible for this particular File
+	// (even though it is generally supported by its DataSource) return an error of type ErrUnsupported.
+	OpenRandom() (RandomReader, error)
+}
+
+// ChildFinder may be implemented by Dirs that can fail to retrieve a child for reasons other than it not existing (for
+// example an OS permission error). If a Dir implements ChildFinder it is used instead of Dir.Child.
+type ChildFinder interface {
+	// FindChild is the same as Dir.Child, except it returns an error if something went wrong. If the child simply
+	// does not exist (and cannot be created) return nil and no error, like Dir.Child does.
+	FindChild(id string, create int) (DataSource, error)
+}
+
+// Describer may be implemented by Files and Dirs that can say where their data comes from (an OS path, the name of an
+// archive, etc). It is used by FileSystem.Mounts and FileSystem.Dump.
+type Describer interface {
+	// Describe returns a short human readable description of the item's location.
+	Describe() string
+}
+
+// Stater may be implemented by Files and Dirs that can provide more information about themselves than File.Size.
+// It is used by FileSystem.Stat.
+type Stater interface {
+	// Stat returns information about the item. Only the size, mode, and modification time are used.
+	Stat() (os.FileInfo, error)
+}
+
+// DirMaker may be implemented by Dirs that can create empty child directories. It is used by FileSystem.Mkdir and
+// FileSystem.MkdirAll.
+type DirMaker interface {
+	// Mkdir creates the child directory id. This is only called if the child does not already exist.
+	Mkdir(id string) error
+}
+
+// MetaSetter may be implemented by Files and Dirs that can store metadata. It is used to preserve metadata when
+// copying items.
+type MetaSetter interface {
+	// SetMeta sets the permission bits and modification time of the item. If the modification time is zero it should
+	// be left alone.
+	SetMeta(perm os.FileMode, modTime time.Time) error
+}
+
+// Renamer may be implemented by Dirs that can natively rename (or move) their children. It is used by
+// FileSystem.Rename when the old and new locations are both in the same mounted DataSource.
+type Renamer interface {
+	// Rename moves the child item id to the Dir to, giving it the name toid. to was retrieved from the same mounted
+	// DataSource as the Dir Rename is called on (possibly using the CreateDir hint, so it may not exist yet), and
+	// nothing exists at toid.
+	// 
+	// If the rename cannot be done natively return an error of type ErrUnsupported and the item will be copied
+	// and then deleted instead.
+	Rename(id string, to Dir, toid string) error
+}
+
+// FileInfo describes an item in a FileSystem, as returned by FileSystem.Stat.
+type FileInfo struct {
+	// The name of the item (the last element of its path).
+	Name string
+	
+	// The size of the item in bytes, always zero for directories.
+	Size int64
+	
+	// The modification time of the item, zero if unknown.
+	ModTime time.Time
+	
+	// The mode of the item. If the item does not implement Stater this is synthesized from the halves it is
+	// mounted on.
+	Mode os.FileMode
+	
+	// IsDir is true if the item is a Dir or a mount point subset.
+	IsDir bool
+	
+	// IsMP is true if the path is a mount point subset (see FileSystem.IsMP).
+	IsMP bool
+	
+	// The mount point and the mounted DataSource that satisfied the lookup.
+	// These are empty for mount point subsets that do not have anything mounted directly on them, and for DataSources
+	// a View does not expose (see View).
+	MountPoint string
+	Source     DataSource
+}
+
 type source struct {
 	mp []string
 	ds DataSource
+	
+	label    string
+	priority int
 }
 
 // FileSystem is the center of an AXIS setup.
@@ -128,46 +225,77 @@ type source struct {
 // write half.
 // 
 // If you mount more than one item on a location they will be tried in order, the first one to work is the one that is
-// used.
+// used. By default this is the order they were mounted in, MountWith can be used to change that.
 // 
-// The zero value of FileSystem is an empty FileSystem ready to use.
+// A FileSystem is safe for concurrent use by multiple goroutines. The mount table is copy-on-write: Mount, Unmount,
+// and SwapMount build a new table and swap it in, so lookups never block and each lookup sees the table either
+// entirely before or entirely after any given change. Operations that do several lookups (List, for example) may see
+// a change part way through. Whether the DataSources themselves are safe for concurrent use is up to their
+// implementations (all the provided ones are).
+// 
+// The zero value of FileSystem is an empty FileSystem ready to use. A FileSystem must not be copied after first use.
 type FileSystem struct {
+	lock  sync.Mutex // Held while the mount table is being changed.
+	table atomic.Pointer[mountTable]
+	
+	alock    sync.Mutex // Held while using the archive cache.
+	archives map[archiveKey]*archive
+	retired  []*archive // Archives waiting to be closed, see hold.
+	epoch    uint64
+	holds    map[uint64]int // Keyed by epoch.
+}
+
+// mountTable holds the read and write halves of a FileSystem, along with the archive openers set with AutoMount and
+// the setting from Whiteouts.
+// Once a table has been stored in a FileSystem it must never be modified.
+type mountTable struct {
 	r []*source
 	w []*source
+	
+	auto      map[string]ArchiveOpener // Keyed by lower case extension.
+	whiteouts bool
 }
 
-/*
-// Dump is a simple debugging function that list all resources mounted for reading to standard output.
-func (fs *FileSystem) Dump() {
-	for _, source := range fs.r {
-		fmt.Printf("%q: (%T)%#v\n", strings.Join(source.mp, "/"), source.ds, source.ds)
+// sources returns the current read or write half of the mount table.
+func (fs *FileSystem) sources(r bool) []*source {
+	t := fs.table.Load()
+	if t == nil {
+		return nil
+	}
+	if r {
+		return t.r
 	}
+	return t.w
+}
+
+// update calls f with a copy of the current mount table, then replaces the current table with the copy.
+func (fs *FileSystem) update(f func(t *mountTable)) {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+	
+	t := &mountTable{auto: map[string]ArchiveOpener{}}
+	if old := fs.table.Load(); old != nil {
+		t.r = append([]*source(nil), old.r...)
+		t.w = append([]*source(nil), old.w...)
+		for ext, open := range old.auto {
+			t.auto[ext] = open
+		}
+		t.whiteouts = old.whiteouts
+	}
+	f(t)
+	fs.table.Store(t)
+	fs.pruneArchives(t)
 }
-*/
 
 // Mount a given DataSource onto the FileSystem at the given path.
 // If rw is true the DataSource is mounted for writing as well as reading.
+// 
+// The DataSource is tried after any already mounted on the same location (unless they were mounted with a negative
+// priority, see MountWith).
+// 
+// Mounting a DataSource returned by Bind so that it would contain itself returns an error of type ErrBadAction.
 func (fs *FileSystem) Mount(path string, ds DataSource, rw bool) error {
-	dirs := validatePath(path)
-	if dirs == nil {
-		return &Error{Path: path, Typ: ErrBadPath}
-	}
-	
-	// Ensure the mounted item implements either File or Dir (or both).
-	_, a := ds.(File); _, b := ds.(Dir)
-	if !a && !b {
-		return &Error{Path: path, Typ: ErrBadAction}
-	}
-	
-	src := &source{
-		mp: dirs,
-		ds: ds,
-	}
-	fs.r = append(fs.r, src)
-	if rw {
-		fs.w = append(fs.w, src)
-	}
-	return nil
+	return fs.MountWith(path, ds, rw, MountOptions{})
 }
 
 // Unmount deletes all mounted DataSources with the given mount point.
@@ -177,10 +305,12 @@ func (fs *FileSystem) Unmount(path string, r bool) error {
 		return &Error{Path: path, Typ: ErrBadPath}
 	}
 	
-	fs.w = unmount(dirs, fs.w)
-	if r {
-		fs.r = unmount(dirs, fs.r)
-	}
+	fs.update(func(t *mountTable) {
+		t.w = unmount(dirs, t.w)
+		if r {
+			t.r = unmount(dirs, t.r)
+		}
+	})
 	return nil
 }
 
@@ -208,7 +338,7 @@ func unmount(dirs []string, sources []*source) []*source {
 }
 
 // SwapMount replaces the first data source with the given mount point and returns the old data source.
-// Returns nil on error.
+// Returns nil on error (including if the new data source is a bind that would create a cycle, see Bind).
 func (fs *FileSystem) SwapMount(path string, ds DataSource, rw bool) DataSource {
 	dirs := validatePath(path)
 	if dirs == nil {
@@ -220,34 +350,59 @@ func (fs *FileSystem) SwapMount(path string, ds DataSource, rw bool) DataSource
 		return nil
 	}
 	
-	rtn := remount(dirs, ds, fs.r)
-	if rw {
-		remount(dirs, ds, fs.w)
-	}
+	var rtn DataSource
+	fs.update(func(t *mountTable) {
+		// Sources are shared by the old tables, so they must be replaced rather than changed.
+		i := findMount(dirs, t.r)
+		if i == -1 || fs.bindCycle(t, dirs, ds) {
+			return
+		}
+		old := t.r[i]
+		src := &source{
+			mp: old.mp,
+			ds: ds,
+			label: old.label,
+			priority: old.priority,
+		}
+		rtn = old.ds
+		t.r[i] = src
+		
+		// If the source was also mounted for writing it is replaced there as well.
+		for k := range t.w {
+			if t.w[k] == old {
+				t.w[k] = src
+			}
+		}
+		if rw {
+			if k := findMount(dirs, t.w); k != -1 && t.w[k] != src {
+				t.w[k] = &source{
+					mp: t.w[k].mp,
+					ds: ds,
+					label: t.w[k].label,
+					priority: t.w[k].priority,
+				}
+			}
+		}
+	})
 	return rtn
 }
 
-func remount(dirs []string, ds DataSource, sources []*source) DataSource {
+// findMount returns the index of the first source with the given mount point, or -1 if there are none.
+func findMount(dirs []string, sources []*source) int {
 	next:
-	for i := 0; i < len(sources); {
+	for i := range sources {
 		if len(dirs) != len(sources[i].mp) {
-			i++
 			continue
 		}
 		
 		for k := range dirs {
 			if dirs[k] != sources[i].mp[k] {
-				i++
 				continue next
 			}
 		}
-		
-		// Mount point matches the list, replace.
-		rtn := sources[i].ds
-		sources[i].ds = ds
-		return rtn
+		return i
 	}
-	return nil
+	return -1
 }
 
 // Returns a list of mount point parts that begin with the given path.
@@ -259,12 +414,7 @@ func (fs *FileSystem) mountSubset(path string, r bool) []string {
 		return nil
 	}
 	
-	var sources []*source
-	if r {
-		sources = fs.r
-	} else {
-		sources = fs.w
-	}
+	sources := fs.sources(r)
 	
 	var rtn []string
 	have := map[string]bool{}
@@ -299,12 +449,7 @@ func (fs *FileSystem) isMP(path string, r bool) bool {
 		return false
 	}
 	
-	var sources []*source
-	if r {
-		sources = fs.r
-	} else {
-		sources = fs.w
-	}
+	sources := fs.sources(r)
 	
 	next:
 	for _, src := range sources {
@@ -348,58 +493,165 @@ func (fs *FileSystem) GetDSsAt(path string, create, r bool) ([]DataSource, error
 		return nil, &Error{Path: path, Typ: ErrBadPath}
 	}
 	
-	var sources []*source
+	c := CreateNone
+	if create {
+		c = CreateFile
+	}
+	
+	matches, err := fs.matches(path, dirs, c, r)
+	if err != nil {
+		return nil, err
+	}
+	dss := make([]DataSource, 0, len(matches))
+	for _, m := range matches {
+		dss = append(dss, m.ds)
+	}
+	return dss, nil
+}
+
+// matches is find, but it returns the appropriate error if nothing matches: ErrBadAction for mount point subsets,
+// ErrNotDir if every failing source had a File in the way, and ErrNotFound otherwise.
+func (fs *FileSystem) matches(path string, dirs []string, create int, r bool) ([]match, error) {
+	var errs MultiError
+	matches := fs.find(dirs, create, r, &errs)
+	if matches != nil {
+		return matches, nil
+	}
+	if fs.isMP(path, r) {
+		return nil, &Error{Path: path, Typ: ErrBadAction}
+	}
+	if errs != nil {
+		// If the only problem was a File where a Dir was expected, say so.
+		typ := ErrNotDir
+		for _, err := range errs {
+			if !errors.Is(err, ErrNotDirSentinel) {
+				typ = ErrNotFound
+				break
+			}
+		}
+		return nil, &Error{Path: path, Typ: typ, Err: errs}
+	}
+	return nil, &Error{Path: path, Typ: ErrNotFound}
+}
+
+// Diagnose returns a MultiError listing every DataSource on the given half that failed while looking up the path, or
+// nil if none did. DataSources that simply do not contain the path are not failures. Each error in the list is a
+// *SourceError.
+// 
+// Diagnose is useful for finding out why an item was not found, or why an item in one DataSource was used instead of
+// an item in a DataSource mounted in front of it.
+func (fs *FileSystem) Diagnose(path string, r bool) error {
+	dirs := validatePath(path)
+	if dirs == nil {
+		return &Error{Path: path, Typ: ErrBadPath}
+	}
+	
+	var errs MultiError
+	fs.find(dirs, CreateNone, r, &errs)
+	if errs != nil {
+		return errs
+	}
+	return nil
+}
+
+// match is a DataSource found by lookup, together with the mounted source it was found in.
+type match struct {
+	src *source
+	ds  DataSource
+	n   int // The index of src in the mount table half it came from.
+}
+
+// lookup does the actual work for GetDSsAt, returning every item that matches the given (already validated) path
+// in mount order.
+// 
+// create is the Dir.Child flag used for the last element of the path. If it is not CreateNone any missing parent
+// directories are created as well.
+func (fs *FileSystem) lookup(dirs []string, create int, r bool) []match {
+	return fs.find(dirs, create, r, nil)
+}
+
+// find is lookup, but if errs is not nil any sources that fail for reasons other than not containing the path
+// have a SourceError added to errs.
+func (fs *FileSystem) find(dirs []string, create int, r bool, errs *MultiError) []match {
+	defer fs.hold()()
+	
+	t := fs.table.Load()
+	if t == nil {
+		return nil
+	}
+	sources := t.w
 	if r {
-		sources = fs.r
-	} else {
-		sources = fs.w
+		sources = t.r
 	}
 	
-	var dss []DataSource
+	// Whiteout markers are only used on the read half, and are never visible themselves.
+	whiteouts := r && t.whiteouts
+	if whiteouts && isMarkerPath(dirs) {
+		return nil
+	}
 	
-	next:
-	for _, src := range sources {
+	var rtn []match
+	for n, src := range sources {
 		// First make sure that the path is a superset of the current source's mount point.
-		i := 0
-		for ; i < len(src.mp); i++ {
-			if i >= len(dirs) || src.mp[i] != dirs[i] {
-				continue next
-			}
+		if !hasPrefix(dirs, src.mp) {
+			continue
 		}
 		
 		// Then try to get a child item from the source that matches the remainder of the path.
-		ds := src.ds
-		var pds DataSource
-		for ; i < len(dirs); i++ {
-			pds = ds
-			pdir, ok := pds.(Dir)
-			if !ok {
-				continue next
-			}
-			
-			c := CreateNone
-			if create && i == len(dirs)-1 {
-				c = CreateFile
-			} else if create {
-				c = CreateDir
-			}
-			
-			ds = pdir.Child(dirs[i], c)
-			if ds == nil {
-				continue next
+		ds, hidden := fs.follow(t, src, dirs, create, whiteouts, errs)
+		if ds != nil {
+			rtn = append(rtn, match{src: src, ds: ds, n: n})
+		}
+		if hidden {
+			// Whiteout markers hide the path in all the sources after this one.
+			break
+		}
+	}
+	return rtn
+}
+
+// follow returns the item at the given path in a single source, or nil if the source does not contain it.
+// The path must be a superset of the source's mount point.
+// 
+// If whiteouts is true follow also checks for whiteout markers along the way, and returns true if the source hides
+// the path from the sources after it.
+func (fs *FileSystem) follow(t *mountTable, src *source, dirs []string, create int, whiteouts bool, errs *MultiError) (DataSource, bool) {
+	hidden := false
+	ds := src.ds
+	for i := len(src.mp); i < len(dirs); i++ {
+		pdir, err := fs.asDir(t, src, dirs[:i], ds)
+		if pdir == nil {
+			if errs != nil {
+				if err == nil {
+					err = NewError(ErrNotDir)
+				}
+				*errs = append(*errs, newSourceError(src, dirs[:i], err))
 			}
+			return nil, hidden
 		}
 		
-		dss = append(dss, ds)
-	}
-	
-	if dss != nil {
-		return dss, nil
-	}
-	if fs.isMP(path, r) {
-		return nil, &Error{Path: path, Typ: ErrBadAction}
+		if whiteouts && !hidden {
+			hidden = pdir.Child(OpaqueMarker, CreateNone) != nil || pdir.Child(WhiteoutPrefix+dirs[i], CreateNone) != nil
+		}
+		
+		c := create
+		if create != CreateNone && i != len(dirs)-1 {
+			c = CreateDir
+		}
+		
+		if f, ok := pdir.(ChildFinder); ok {
+			ds, err = f.FindChild(dirs[i], c)
+		} else {
+			ds = pdir.Child(dirs[i], c)
+		}
+		if err != nil && errs != nil {
+			*errs = append(*errs, newSourceError(src, dirs[:i+1], err))
+		}
+		if ds == nil || err != nil {
+			return nil, hidden
+		}
 	}
-	return nil, &Error{Path: path, Typ: ErrNotFound}
+	return ds, hidden
 }
 
 // Exists returns true if the path points to a valid DataSource or a mount point subset.
@@ -446,10 +698,101 @@ func (fs *FileSystem) Size(path string) int64 {
 	return f.Size()
 }
 
+// Stat returns information about the item at the given path.
+// 
+// If the item implements Stater the information it provides is used, otherwise a reasonable answer is synthesized
+// from what is available (Size for Files, and the halves the source is mounted on for the mode). Mount point subsets
+// (and the root, which always exists) are reported as read-only directories with no Source.
+func (fs *FileSystem) Stat(path string) (*FileInfo, error) {
+	defer fs.hold()()
+	
+	dirs := validatePath(path)
+	if dirs == nil {
+		return nil, &Error{Path: path, Typ: ErrBadPath}
+	}
+	
+	info := &FileInfo{
+		IsMP: fs.isMP(path, true),
+	}
+	if len(dirs) > 0 {
+		info.Name = dirs[len(dirs)-1]
+	}
+	
+	matches := fs.lookup(dirs, CreateNone, true)
+	if len(matches) == 0 {
+		if info.IsMP || len(dirs) == 0 {
+			info.IsDir = true
+			info.Mode = os.ModeDir | 0555
+			return info, nil
+		}
+		return nil, &Error{Path: path, Typ: ErrNotFound}
+	}
+	
+	m := matches[0]
+	info.MountPoint = strings.Join(m.src.mp, "/")
+	info.Source = m.src.ds
+	_, info.IsDir = m.ds.(Dir)
+	
+	if s, ok := m.ds.(Stater); ok {
+		si, err := s.Stat()
+		if err != nil {
+			return nil, wrapError(err, path)
+		}
+		info.ModTime = si.ModTime()
+		info.Mode = si.Mode()
+		if !info.IsDir {
+			info.Size = si.Size()
+		}
+	} else {
+		info.Mode = 0444
+		if info.IsDir {
+			info.Mode = 0555
+		} else {
+			info.Size = m.ds.(File).Size()
+		}
+		for _, src := range fs.sources(false) {
+			if src == m.src {
+				info.Mode |= 0200
+				break
+			}
+		}
+	}
+	
+	// Whatever the item claims, the interfaces it implements are what matters.
+	if info.IsDir {
+		info.Mode |= os.ModeDir
+	} else {
+		info.Mode &^= os.ModeDir
+	}
+	return info, nil
+}
+
 // Delete attempts to delete the item at the given path. This may or may not work. Deleting is always carried out on the
 // write portion of the FileSystem, objects on the read portion will not be effected unless they are also mounted for
 // writing. Only the first item found is deleted.
+// 
+// If whiteouts are turned on (see Whiteouts) and the item can still be found on the read half after it is deleted from
+// the write half (or if it was never on the write half), it is hidden with a whiteout marker instead.
 func (fs *FileSystem) Delete(path string) error {
+	err := fs.delete(path)
+	if e, ok := err.(*Error); err != nil && (!ok || e.Typ != ErrNotFound) {
+		return err
+	}
+	
+	if t := fs.table.Load(); t == nil || !t.whiteouts {
+		return err
+	}
+	dirs := validatePath(path)
+	if len(dirs) == 0 {
+		return err
+	}
+	if ok, werr := fs.whiteout(dirs, false); ok || werr != nil {
+		return werr
+	}
+	return err
+}
+
+func (fs *FileSystem) delete(path string) error {
 	npath, last := trimLastPath(path)
 	
 	dss, err := fs.GetDSsAt(npath, false, false)
@@ -477,18 +820,19 @@ func (fs *FileSystem) Delete(path string) error {
 // If the path is a mount point subset this may return more mount point subsets or a mix of mount point subsets and
 // data sources!
 func (fs *FileSystem) List(path string) []string {
-	dss, err := fs.GetDSsAt(path, false, true)
+	defer fs.hold()()
+	
+	ds, err := fs.dirsAt(path)
 	if err != nil {
 		// Treat the path like a directory of directories if it is a mount point subset.
-		return fs.mountSubset(path, true)
+		return fs.visibleSubset(path)
 	}
 	
 	have := map[string]bool{}
 	var rtn []string
-	for _, ds := range dss {
-		d, ok := ds.(Dir)
-		if ok {
-			for _, item := range d.List() {
+	for _, d := range ds {
+		if d.dir != nil {
+			for _, item := range d.names {
 				if !have[item] {
 					have[item] = true
 					rtn = append(rtn, item)
@@ -498,7 +842,7 @@ func (fs *FileSystem) List(path string) []string {
 	}
 	// This should only matter in cases where mount points overlap data sources.
 	// Appending a nil slice to a nil slice results in a nil slice, yes I checked.
-	return append(rtn, fs.mountSubset(path, true)...)
+	return append(rtn, fs.visibleSubset(path)...)
 }
 
 // ListDirs returns a slice of all the items that are Dirs in the given Dir. If the item at the path is not a Dir
@@ -510,25 +854,25 @@ func (fs *FileSystem) List(path string) []string {
 // If the path is a mount point subset this may return more mount point subsets or a mix of mount point subsets and
 // data sources!
 func (fs *FileSystem) ListDirs(path string) []string {
-	dss, err := fs.GetDSsAt(path, false, true)
+	defer fs.hold()()
+	
+	ds, err := fs.dirsAt(path)
 	if err != nil {
 		// Treat the path like a directory of directories if it is a mount point subset.
-		return fs.mountSubset(path, true)
+		return fs.visibleSubset(path)
 	}
 	
 	have := map[string]bool{}
 	var rtn []string
-	for _, ds := range dss {
-		d, ok := ds.(Dir)
-		if !ok {
+	for _, d := range ds {
+		if d.dir == nil {
 			continue
 		}
-		children := d.List()
 		
-		for _, child := range children {
+		for _, child := range d.names {
 			if !have[child] {
 				have[child] = true
-				cds := d.Child(child, 0)
+				cds := d.dir.Child(child, 0)
 				if _, ok := cds.(Dir); ok {
 					rtn = append(rtn, child)
 				}
@@ -537,7 +881,7 @@ func (fs *FileSystem) ListDirs(path string) []string {
 	}
 	// This should only matter in cases where mount points overlap data sources.
 	// Appending a nil slice to a nil slice results in a nil slice, yes I checked.
-	return append(rtn, fs.mountSubset(path, true)...)
+	return append(rtn, fs.visibleSubset(path)...)
 }
 
 // ListFiles returns a slice of all the items that are Files in the given Dir. If the item at the path is not a Dir
@@ -546,24 +890,24 @@ func (fs *FileSystem) ListDirs(path string) []string {
 // The order of the returned list is undefined, or more correctly, is defined by the individual Dir implementations.
 // Most of the time this means lexically by filename, but not always.
 func (fs *FileSystem) ListFiles(path string) []string {
-	dss, err := fs.GetDSsAt(path, false, true)
+	defer fs.hold()()
+	
+	ds, err := fs.dirsAt(path)
 	if err != nil {
 		return nil
 	}
 	
 	have := map[string]bool{}
 	var rtn []string
-	for _, ds := range dss {
-		d, ok := ds.(Dir)
-		if !ok {
+	for _, d := range ds {
+		if d.dir == nil {
 			return nil
 		}
-		children := d.List()
 		
-		for _, child := range children {
+		for _, child := range d.names {
 			if !have[child] {
 				have[child] = true
-				cds := d.Child(child, 0)
+				cds := d.dir.Child(child, 0)
 				if _, ok := cds.(File); ok {
 					rtn = append(rtn, child)
 				}
@@ -573,20 +917,97 @@ func (fs *FileSystem) ListFiles(path string) []string {
 	return rtn
 }
 
+// dirList is a Dir along with the names of its visible children.
+type dirList struct {
+	dir   Dir
+	names []string
+}
+
+// dirsAt returns the Dirs at the given path on the read half, along with their contents. Files are opened as archives
+// if possible (see AutoMount), any that can't be have a nil dir.
+// 
+// If whiteouts are turned on the markers are removed from the lists, along with any items they hide.
+func (fs *FileSystem) dirsAt(path string) ([]dirList, error) {
+	dirs := validatePath(path)
+	if dirs == nil {
+		return nil, &Error{Path: path, Typ: ErrBadPath}
+	}
+	
+	matches := fs.lookup(dirs, CreateNone, true)
+	if len(matches) == 0 {
+		return nil, &Error{Path: path, Typ: ErrNotFound}
+	}
+	
+	t := fs.table.Load()
+	var w whiteouts
+	rtn := make([]dirList, 0, len(matches))
+	for _, m := range matches {
+		d, _ := fs.asDir(t, m.src, dirs, m.ds)
+		l := dirList{dir: d}
+		if d != nil {
+			l.names = d.List()
+			if t.whiteouts {
+				l.names = w.filter(l.names)
+			}
+		}
+		rtn = append(rtn, l)
+	}
+	return rtn, nil
+}
+
 // Read opens the File at the given path for reading.
 func (fs *FileSystem) Read(path string) (io.ReadCloser, error) {
+	release := fs.hold()
 	ds, err := fs.GetDSAt(path, false, true)
 	if err != nil {
+		release()
 		return nil, err
 	}
 	
 	f, ok := ds.(File)
 	if !ok {
-		return nil, &Error{Path: path, Typ: ErrBadAction}
+		release()
+		return nil, &Error{Path: path, Typ: ErrIsDir}
 	}
 	
 	rc, err := f.Read()
-	return rc, wrapError(err, path)
+	if err != nil || !fs.hasArchives() {
+		release()
+		return rc, wrapError(err, path)
+	}
+	// The File may be inside an archive, so keep it open until the reader is closed.
+	return &heldReader{ReadCloser: rc, release: release}, nil
+}
+
+// OpenRandom opens the File at the given path for random access reading.
+// 
+// Returns an error of type ErrUnsupported if the File does not implement RandomAccess, or does not support random
+// access for this particular File (for example compressed zip entries, unless a decompression cache is used).
+func (fs *FileSystem) OpenRandom(path string) (RandomReader, error) {
+	release := fs.hold()
+	ds, err := fs.GetDSAt(path, false, true)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	
+	f, ok := ds.(File)
+	if !ok {
+		release()
+		return nil, &Error{Path: path, Typ: ErrIsDir}
+	}
+	ra, ok := f.(RandomAccess)
+	if !ok {
+		release()
+		return nil, &Error{Path: path, Typ: ErrUnsupported}
+	}
+	
+	r, err := ra.OpenRandom()
+	if err != nil || !fs.hasArchives() {
+		release()
+		return r, wrapError(err, path)
+	}
+	return &heldRandomReader{RandomReader: r, release: release}, nil
 }
 
 // ReadAll reads the File at the given path and returns it's contents.
@@ -610,7 +1031,7 @@ func (fs *FileSystem) Write(path string) (io.WriteCloser, error) {
 	
 	f, ok := ds.(File)
 	if !ok {
-		return nil, &Error{Path: path, Typ: ErrBadAction}
+		return nil, &Error{Path: path, Typ: ErrIsDir}
 	}
 	
 	wc, err := f.Write()
@@ -618,7 +1039,18 @@ func (fs *FileSystem) Write(path string) (io.WriteCloser, error) {
 }
 
 // Append opens the file at the given path for writing. The write cursor is set beyond any existing file contents.
+// 
+// If the File is on the read half, but not on the write half, it is copied to the write half first (see Copy) so that
+// the existing contents are kept.
 func (fs *FileSystem) Append(path string) (io.WriteCloser, error) {
+	if ds, err := fs.GetDSAt(path, false, true); err == nil {
+		if _, ok := ds.(File); ok {
+			if err := fs.Copy(path, path); err != nil {
+				return nil, err
+			}
+		}
+	}
+	
 	ds, err := fs.GetDSAt(path, true, false)
 	if err != nil {
 		return nil, err
@@ -626,7 +1058,7 @@ func (fs *FileSystem) Append(path string) (io.WriteCloser, error) {
 	
 	f, ok := ds.(File)
 	if !ok {
-		return nil, &Error{Path: path, Typ: ErrBadAction}
+		return nil, &Error{Path: path, Typ: ErrIsDir}
 	}
 	
 	wc, err := f.Append()
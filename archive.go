@@ -0,0 +1,284 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package axis2
+
+import "io"
+import "strings"
+import "sync"
+import "time"
+
+// ArchiveOpener opens a File as a Dir, generally by treating it as an archive. See FileSystem.AutoMount.
+// 
+// If the returned Dir implements io.Closer it is closed when it is no longer needed.
+type ArchiveOpener func(file File) (Dir, error)
+
+// AutoMount makes the FileSystem treat Files with the given extension (for example ".zip") as directories whenever a
+// path goes through them, so "mods/pack.zip/data/x.json" reads "data/x.json" from inside "mods/pack.zip". List,
+// ListDirs, and ListFiles also list the contents of such Files, but they are still Files for every other purpose
+// (so they can be read, copied, etc like normal, and Walk does not descend into them).
+// 
+// Archives are opened the first time they are needed, then kept open (and reused) for as long as their size and
+// modification time stay the same, and the DataSource they are in stays mounted. Archives that are no longer needed are
+// only closed once every lookup that started before then has finished, and every reader those lookups returned (from
+// Read or OpenRandom) has been closed. Archives that fail to open are treated like any other File, but the error is
+// reported (see SourceError) if the path cannot be found in any other DataSource.
+// 
+// Extensions are not case sensitive, and if more than one extension matches a name the longest one is used. Passing
+// a nil ArchiveOpener turns off auto mounting for the extension.
+func (fs *FileSystem) AutoMount(ext string, open ArchiveOpener) {
+	ext = strings.ToLower(ext)
+	fs.update(func(t *mountTable) {
+		if open == nil {
+			delete(t.auto, ext)
+			return
+		}
+		t.auto[ext] = open
+	})
+}
+
+type archiveKey struct {
+	src  *source
+	path string
+}
+
+type archive struct {
+	dir  Dir
+	size int64
+	mod  time.Time
+	
+	epoch uint64 // Only set once the archive is retired.
+}
+
+// asDir returns ds as a Dir, opening it as an archive if needed and possible. Returns nil if ds cannot be used as a
+// Dir, along with the error from the ArchiveOpener if there is one. dirs is the path of ds, and src is the source it
+// was found in.
+func (fs *FileSystem) asDir(t *mountTable, src *source, dirs []string, ds DataSource) (Dir, error) {
+	if d, ok := ds.(Dir); ok {
+		return d, nil
+	}
+	
+	file, ok := ds.(File)
+	if !ok || t == nil || len(t.auto) == 0 || len(dirs) == 0 {
+		return nil, nil
+	}
+	
+	// Use the longest matching extension, so ".tar.gz" wins over ".gz".
+	name := strings.ToLower(dirs[len(dirs)-1])
+	match := ""
+	for ext := range t.auto {
+		if len(ext) > len(match) && strings.HasSuffix(name, ext) {
+			match = ext
+		}
+	}
+	if match == "" {
+		return nil, nil
+	}
+	return fs.openArchive(archiveKey{src: src, path: strings.Join(dirs, "/")}, file, t.auto[match])
+}
+
+func (fs *FileSystem) openArchive(key archiveKey, file File, open ArchiveOpener) (Dir, error) {
+	size, mod := file.Size(), time.Time{}
+	if s, ok := file.(Stater); ok {
+		if info, err := s.Stat(); err == nil {
+			mod = info.ModTime()
+		}
+	}
+	
+	fs.alock.Lock()
+	if a, ok := fs.archives[key]; ok && a.size == size && a.mod.Equal(mod) {
+		fs.alock.Unlock()
+		return a.dir, nil
+	}
+	fs.alock.Unlock()
+	
+	// The opener may use this FileSystem (for example if the File comes from Bind), so the lock cannot be held.
+	dir, err := open(file)
+	if err != nil {
+		return nil, err
+	}
+	
+	fs.alock.Lock()
+	a, ok := fs.archives[key]
+	switch {
+	case ok && a.size == size && a.mod.Equal(mod):
+		// Opened by someone else in the meantime. Nobody else has seen this copy, so it can be closed right away.
+		fs.alock.Unlock()
+		closeArchive(&archive{dir: dir})
+		return a.dir, nil
+	case ok:
+		fs.retire(a)
+		delete(fs.archives, key)
+	}
+	
+	a = &archive{dir: dir, size: size, mod: mod}
+	if fs.mounted(key.src) {
+		if fs.archives == nil {
+			fs.archives = map[archiveKey]*archive{}
+		}
+		fs.archives[key] = a
+	} else {
+		// Unmounted while it was being opened, so don't keep it.
+		fs.retire(a)
+	}
+	done := fs.collect()
+	fs.alock.Unlock()
+	
+	closeArchives(done)
+	return dir, nil
+}
+
+// mounted returns true if the source is in the current mount table.
+func (fs *FileSystem) mounted(src *source) bool {
+	t := fs.table.Load()
+	if t == nil {
+		return false
+	}
+	return indexOf(src, t.r) != -1 || indexOf(src, t.w) != -1
+}
+
+// pruneArchives retires any cached archives that belong to sources that are no longer mounted.
+func (fs *FileSystem) pruneArchives(t *mountTable) {
+	fs.alock.Lock()
+	mounted := map[*source]bool{}
+	for _, src := range t.r {
+		mounted[src] = true
+	}
+	for _, src := range t.w {
+		mounted[src] = true
+	}
+	for key, a := range fs.archives {
+		if !mounted[key.src] {
+			fs.retire(a)
+			delete(fs.archives, key)
+		}
+	}
+	done := fs.collect()
+	fs.alock.Unlock()
+	
+	closeArchives(done)
+}
+
+// Archives that are no longer cached may still be in use by lookups and readers that started earlier, so instead of
+// being closed right away they are retired. Every lookup (and every reader returned by Read and OpenRandom) holds the
+// current epoch, and retiring an archive starts a new one. Retired archives are closed once every hold from their
+// epoch or earlier has been released.
+
+// retire marks an archive that was removed from the cache to be closed later. The lock must be held.
+func (fs *FileSystem) retire(a *archive) {
+	a.epoch = fs.epoch
+	fs.epoch++
+	fs.retired = append(fs.retired, a)
+}
+
+// collect removes the retired archives that are no longer in use from the list and returns them, so they can be
+// closed once the lock is released. The lock must be held.
+func (fs *FileSystem) collect() []*archive {
+	if len(fs.retired) == 0 {
+		return nil
+	}
+	
+	oldest := fs.epoch
+	for e := range fs.holds {
+		if e < oldest {
+			oldest = e
+		}
+	}
+	
+	var done []*archive
+	keep := fs.retired[:0]
+	for _, a := range fs.retired {
+		if a.epoch < oldest {
+			done = append(done, a)
+		} else {
+			keep = append(keep, a)
+		}
+	}
+	fs.retired = keep
+	return done
+}
+
+// hold keeps any archive that is currently cached from being closed until the returned function is called. The
+// function may be called more than once.
+func (fs *FileSystem) hold() func() {
+	fs.alock.Lock()
+	e := fs.epoch
+	if fs.holds == nil {
+		fs.holds = map[uint64]int{}
+	}
+	fs.holds[e]++
+	fs.alock.Unlock()
+	
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			fs.alock.Lock()
+			if fs.holds[e]--; fs.holds[e] == 0 {
+				delete(fs.holds, e)
+			}
+			done := fs.collect()
+			fs.alock.Unlock()
+			
+			closeArchives(done)
+		})
+	}
+}
+
+// hasArchives returns true if any archives are open, cached or not.
+func (fs *FileSystem) hasArchives() bool {
+	fs.alock.Lock()
+	defer fs.alock.Unlock()
+	return len(fs.archives) != 0 || len(fs.retired) != 0
+}
+
+func closeArchive(a *archive) {
+	if c, ok := a.dir.(io.Closer); ok {
+		c.Close()
+	}
+}
+
+func closeArchives(as []*archive) {
+	for _, a := range as {
+		closeArchive(a)
+	}
+}
+
+// heldReader and heldRandomReader release a hold (see FileSystem.hold) when they are closed.
+type heldReader struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r *heldReader) Close() error {
+	defer r.release()
+	return r.ReadCloser.Close()
+}
+
+type heldRandomReader struct {
+	RandomReader
+	release func()
+}
+
+func (r *heldRandomReader) Close() error {
+	defer r.release()
+	return r.RandomReader.Close()
+}
@@ -0,0 +1,339 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package axis2_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+	
+	"github.com/milochristiansen/axis2"
+	"github.com/milochristiansen/axis2/sources"
+	"github.com/milochristiansen/axis2/sources/mem"
+	axiszip "github.com/milochristiansen/axis2/sources/zip"
+)
+
+func TestAutoMount(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "mods"), 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "mods", "Pack.ZIP"), data, 0666); err != nil {
+		t.Fatal(err)
+	}
+	
+	afs := new(axis2.FileSystem)
+	afs.Mount("", sources.NewOSDir(dir), false)
+	if afs.Exists("mods/Pack.ZIP/a/x.txt") {
+		t.Fatal("archive traversed before AutoMount was called")
+	}
+	
+	afs.AutoMount(".zip", axiszip.OpenFile)
+	content, err := afs.ReadAll("mods/Pack.ZIP/a/x.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "x.txt" {
+		t.Errorf("unexpected contents: %q", content)
+	}
+	
+	items := afs.ListDirs("mods/Pack.ZIP")
+	if len(items) != 1 || items[0] != "a" {
+		t.Errorf("unexpected ListDirs result: %v", items)
+	}
+	if info, err := afs.Stat("mods/Pack.ZIP"); err != nil || info.IsDir {
+		t.Errorf("archive should still be a file: %v %v", info, err)
+	}
+	
+	afs.AutoMount(".zip", nil)
+	if afs.Exists("mods/Pack.ZIP/a/x.txt") {
+		t.Error("archive traversed after AutoMount was turned off")
+	}
+	afs.Unmount("", true)
+}
+
+func TestAutoMountNested(t *testing.T) {
+	// The inner zip is stored without compression so it can be read without loading it into memory.
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: "inner.zip", Method: zip.Store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(data)
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	
+	outer, err := axiszip.NewRawDir(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	
+	afs := new(axis2.FileSystem)
+	afs.Mount("mods", outer, false)
+	afs.AutoMount(".zip", axiszip.OpenFile)
+	
+	files := afs.ListFiles("mods/inner.zip/a")
+	sort.Strings(files)
+	if strings.Join(files, " ") != "x.txt y.txt z.txt" {
+		t.Errorf("unexpected ListFiles result: %v", files)
+	}
+	
+	ds, err := afs.GetDSAt("mods/inner.zip", false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ra, ok := ds.(axis2.RandomAccess)
+	if !ok {
+		t.Fatal("zip files do not implement RandomAccess")
+	}
+	r, err := ra.OpenRandom()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+	
+	content, err := afs.ReadAll("mods/inner.zip/b.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "b.txt" {
+		t.Errorf("unexpected contents: %q", content)
+	}
+}
+
+func TestAutoMountReentrant(t *testing.T) {
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: "sub/inner.zip", Method: zip.Store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(data)
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	
+	afs := new(axis2.FileSystem)
+	afs.Mount("", mem.NewDir(), true)
+	if err := afs.WriteAll("shared/outer.zip", buf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	afs.AutoMount(".zip", axiszip.OpenFile)
+	
+	// Opening inner.zip through the bind reads it from this FileSystem while the archive is being opened.
+	bound, err := afs.Bind("shared/outer.zip/sub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	afs.Mount("alias", bound, false)
+	
+	done := make(chan error, 1)
+	go func() {
+		content, err := afs.ReadAll("alias/inner.zip/a/x.txt")
+		if err == nil && string(content) != "x.txt" {
+			err = fmt.Errorf("unexpected contents: %q", content)
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("deadlock while opening an archive")
+	}
+}
+
+// closeDir records when an archive is closed.
+type closeDir struct {
+	axis2.Dir
+	closed *int
+}
+
+func (d closeDir) Close() error {
+	*d.closed++
+	return d.Dir.(io.Closer).Close()
+}
+
+func TestAutoMountClose(t *testing.T) {
+	closed := 0
+	opener := func(file axis2.File) (axis2.Dir, error) {
+		dir, err := axiszip.OpenFile(file)
+		if err != nil {
+			return nil, err
+		}
+		return closeDir{dir, &closed}, nil
+	}
+	
+	afs := new(axis2.FileSystem)
+	afs.Mount("", mem.NewDir(), true)
+	if err := afs.WriteAll("pack.zip", data); err != nil {
+		t.Fatal(err)
+	}
+	afs.AutoMount(".zip", opener)
+	
+	// Changing the archive does not close the old copy while a reader from it is open.
+	r, err := afs.Read("pack.zip/a/x.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := afs.WriteAll("pack.zip", append(append([]byte(nil), data...), 0)); err != nil {
+		t.Fatal(err)
+	}
+	if !afs.Exists("pack.zip/a/y.txt") {
+		t.Fatal("changed archive could not be opened")
+	}
+	if closed != 0 {
+		t.Error("archive closed while a reader was still open")
+	}
+	content, err := io.ReadAll(r)
+	if err != nil || string(content) != "x.txt" {
+		t.Errorf("unexpected contents: %q (%v)", content, err)
+	}
+	r.Close()
+	if closed != 1 {
+		t.Errorf("old archive not closed after the reader was: %v", closed)
+	}
+	
+	// The same goes for unmounting.
+	ra, err := afs.OpenRandom("pack.zip/b.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	afs.Unmount("", true)
+	if closed != 1 {
+		t.Error("archive closed on unmount while a reader was still open")
+	}
+	ra.Close()
+	ra.Close()
+	if closed != 2 {
+		t.Errorf("unexpected number of archives closed: %v", closed)
+	}
+}
+
+func TestAutoMountLongest(t *testing.T) {
+	opener := func(name string) axis2.ArchiveOpener {
+		return func(file axis2.File) (axis2.Dir, error) {
+			dir := mem.NewDir()
+			afs := new(axis2.FileSystem)
+			afs.Mount("", dir, true)
+			return dir, afs.WriteAll(name, nil)
+		}
+	}
+	
+	// Extensions are kept in a map, so try a few times in case the iteration order happens to be right.
+	for i := 0; i < 20; i++ {
+		afs := new(axis2.FileSystem)
+		afs.Mount("", mem.NewDir(), true)
+		if err := afs.WriteAll("x.tar.gz", nil); err != nil {
+			t.Fatal(err)
+		}
+		afs.AutoMount(".gz", opener("gz"))
+		afs.AutoMount(".tar.gz", opener("tar.gz"))
+		afs.AutoMount(".zip", opener("zip"))
+		
+		items := afs.List("x.tar.gz")
+		if len(items) != 1 || items[0] != "tar.gz" {
+			t.Fatalf("wrong opener used: %v", items)
+		}
+	}
+}
+
+func TestOpenRandom(t *testing.T) {
+	afs := new(axis2.FileSystem)
+	afs.Mount("", mem.NewDir(), true)
+	if err := afs.WriteAll("mem.txt", []byte("mem.txt")); err != nil {
+		t.Fatal(err)
+	}
+	
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "os.txt"), []byte("os.txt"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	afs.Mount("os", sources.NewOSDir(dir), false)
+	
+	// A zip file with compressed files, since the test data only has stored ones.
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	for name, content := range map[string]string{"a.txt": "a.txt", "big.txt": strings.Repeat("big", 10) + ".txt"} {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	
+	cached, err := axiszip.NewCachedDir(bytes.NewReader(buf.Bytes()), int64(buf.Len()), 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	afs.Mount("cached", cached, false)
+	
+	plain, err := axiszip.NewRawDir(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	afs.Mount("plain", plain, false)
+	
+	// Every file ends with ".txt", so read that back from the end.
+	for _, path := range []string{"mem.txt", "os/os.txt", "cached/a.txt", "plain/a/x.txt"} {
+		r, err := afs.OpenRandom(path)
+		if err != nil {
+			t.Fatalf("%v: %v", path, err)
+		}
+		end, err := r.Seek(0, io.SeekEnd)
+		if err != nil {
+			t.Fatalf("%v: %v", path, err)
+		}
+		tail := make([]byte, 4)
+		if _, err := r.ReadAt(tail, end-4); err != nil || string(tail) != ".txt" {
+			t.Errorf("%v: unexpected ReadAt result: %q %v", path, tail, err)
+		}
+		r.Close()
+	}
+	
+	// Files that are too big for the cache, and compressed files without a cache, do not support random access.
+	deflated, err := axiszip.NewRawDir(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	afs.Mount("deflated", deflated, false)
+	for _, path := range []string{"cached/big.txt", "deflated/a.txt", "plain/a"} {
+		if _, err := afs.OpenRandom(path); err == nil {
+			t.Errorf("%v: OpenRandom succeeded", path)
+		}
+	}
+}
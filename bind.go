@@ -0,0 +1,242 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package axis2
+
+import "io"
+import "os"
+import "strings"
+
+// Bind returns a DataSource that exposes the item at the given path, so that it can be mounted somewhere else in this
+// FileSystem (or in a different FileSystem). For example to make "shared/textures" available as "modA/textures" as
+// well:
+// 
+//	ds, err := fs.Bind("shared/textures")
+//	if err != nil {
+//		return err
+//	}
+//	fs.Mount("modA/textures", ds, true)
+// 
+// Everything done with the returned DataSource is routed through the FileSystem, so reads use its read half and
+// writes use its write half (mounting the DataSource for writing does not make a read-only path writable). Changes to
+// the mount table of the FileSystem are visible through the DataSource right away.
+// 
+// If the path is a Dir (or a mount point subset) the returned DataSource is a Dir, otherwise it is a File. If nothing
+// exists at the path an error of type ErrNotFound is returned.
+// 
+// Mounting the returned DataSource so that a path would contain itself (for example binding "a" at "a/b", or "a/b" at
+// "a") is not allowed, Mount returns an error of type ErrBadAction if you try. This includes cycles that go through
+// more than one bind or FileSystem.
+func (fs *FileSystem) Bind(path string) (DataSource, error) {
+	dirs := validatePath(path)
+	if dirs == nil {
+		return nil, &Error{Path: path, Typ: ErrBadPath}
+	}
+	path = strings.Join(dirs, "/")
+	
+	if fs.IsDir(path) {
+		return &bindDir{fs: fs, path: path}, nil
+	}
+	if fs.Exists(path) {
+		return &bindFile{fs: fs, path: path}, nil
+	}
+	return nil, &Error{Path: path, Typ: ErrNotFound}
+}
+
+type bindDir struct {
+	fs   *FileSystem
+	path string
+}
+
+type bindFile struct {
+	fs   *FileSystem
+	path string
+}
+
+func (dir *bindDir) Child(id string, create int) DataSource {
+	ds, _ := dir.FindChild(id, create)
+	return ds
+}
+
+func (dir *bindDir) FindChild(id string, create int) (DataSource, error) {
+	path := joinPath(dir.path, id)
+	ds, err := dir.fs.GetDSAt(path, false, true)
+	if err != nil {
+		if dir.fs.isMP(path, true) {
+			return &bindDir{fs: dir.fs, path: path}, nil
+		}
+		if e, ok := err.(*Error); !ok || e.Typ != ErrNotFound {
+			return nil, err
+		}
+		
+		// The item will be created by the FileSystem's write half when it is written to.
+		switch create {
+		case CreateDir:
+			return &bindDir{fs: dir.fs, path: path}, nil
+		case CreateFile:
+			return &bindFile{fs: dir.fs, path: path}, nil
+		}
+		return nil, nil
+	}
+	
+	if _, ok := ds.(Dir); ok {
+		return &bindDir{fs: dir.fs, path: path}, nil
+	}
+	return &bindFile{fs: dir.fs, path: path}, nil
+}
+
+func (dir *bindDir) Delete(id string) error {
+	return dir.fs.Delete(joinPath(dir.path, id))
+}
+
+func (dir *bindDir) List() []string {
+	return dir.fs.List(dir.path)
+}
+
+func (dir *bindDir) Mkdir(id string) error {
+	return dir.fs.Mkdir(joinPath(dir.path, id))
+}
+
+// Rename is only supported when both Dirs are bound from the same FileSystem.
+func (dir *bindDir) Rename(id string, to Dir, toid string) error {
+	tdir, ok := to.(*bindDir)
+	if !ok || tdir.fs != dir.fs {
+		return NewError(ErrUnsupported)
+	}
+	return dir.fs.Rename(joinPath(dir.path, id), joinPath(tdir.path, toid))
+}
+
+func (dir *bindDir) Stat() (os.FileInfo, error) {
+	return bindStat(dir.fs, dir.path)
+}
+
+// Describe returns the bound path.
+func (dir *bindDir) Describe() string {
+	return bindDescribe(dir.path)
+}
+
+func (file *bindFile) Read() (io.ReadCloser, error) {
+	return file.fs.Read(file.path)
+}
+
+func (file *bindFile) Write() (io.WriteCloser, error) {
+	return file.fs.Write(file.path)
+}
+
+func (file *bindFile) Append() (io.WriteCloser, error) {
+	return file.fs.Append(file.path)
+}
+
+func (file *bindFile) Size() int64 {
+	return file.fs.Size(file.path)
+}
+
+func (file *bindFile) OpenRandom() (RandomReader, error) {
+	return file.fs.OpenRandom(file.path)
+}
+
+func (file *bindFile) Stat() (os.FileInfo, error) {
+	return bindStat(file.fs, file.path)
+}
+
+// Describe returns the bound path.
+func (file *bindFile) Describe() string {
+	return bindDescribe(file.path)
+}
+
+func bindStat(fs *FileSystem, path string) (os.FileInfo, error) {
+	info, err := fs.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	return newIOInfo(info.Name, info), nil
+}
+
+func bindDescribe(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
+
+// bound returns the FileSystem and path a DataSource returned by Bind exposes.
+func bound(ds DataSource) (*FileSystem, []string, bool) {
+	switch b := ds.(type) {
+	case *bindDir:
+		return b.fs, validatePath(b.path), true
+	case *bindFile:
+		return b.fs, validatePath(b.path), true
+	}
+	return nil, nil, false
+}
+
+// bindCycle returns true if mounting ds at dirs (with t as the new mount table) would make some path contain itself.
+// 
+// Every bind reachable from ds is followed (including binds mounted in other FileSystems), looking for one that leads
+// back to a path that contains, or is contained by, the new mount point.
+func (fs *FileSystem) bindCycle(t *mountTable, dirs []string, ds DataSource) bool {
+	type target struct {
+		fs   *FileSystem
+		path string
+	}
+	seen := map[target]bool{}
+	
+	var visit func(bfs *FileSystem, bdirs []string) bool
+	visit = func(bfs *FileSystem, bdirs []string) bool {
+		if bfs == fs && (hasPrefix(bdirs, dirs) || hasPrefix(dirs, bdirs)) {
+			return true
+		}
+		key := target{bfs, strings.Join(bdirs, "/")}
+		if seen[key] {
+			return false
+		}
+		seen[key] = true
+		
+		bt := bfs.table.Load()
+		if bfs == fs {
+			bt = t
+		}
+		if bt == nil {
+			return false
+		}
+		for _, src := range append(bt.r[:len(bt.r):len(bt.r)], bt.w...) {
+			// Only binds mounted inside the bound path, or that the bound path is inside, can lead anywhere.
+			if !hasPrefix(src.mp, bdirs) && !hasPrefix(bdirs, src.mp) {
+				continue
+			}
+			nfs, ndirs, ok := bound(src.ds)
+			if !ok {
+				continue
+			}
+			if len(bdirs) > len(src.mp) {
+				ndirs = append(ndirs, bdirs[len(src.mp):]...)
+			}
+			if visit(nfs, ndirs) {
+				return true
+			}
+		}
+		return false
+	}
+	
+	bfs, bdirs, ok := bound(ds)
+	return ok && visit(bfs, bdirs)
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package axis2_test
+
+import (
+	"sync"
+	"testing"
+	
+	"github.com/milochristiansen/axis2"
+	"github.com/milochristiansen/axis2/sources/zip"
+)
+
+// These tests are mostly useful when run with the race detector (go test -race).
+
+func TestConcurrentMount(t *testing.T) {
+	afs := new(axis2.FileSystem)
+	ds, err := zip.NewRawDir(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	afs.Mount("base", ds, false)
+	
+	var readers, writers sync.WaitGroup
+	stop := make(chan struct{})
+	
+	// Readers: "base" is never unmounted, so these must always work.
+	for i := 0; i < 4; i++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				
+				content, err := afs.ReadAll("base/a/y.txt")
+				if err != nil || string(content) != "y.txt" {
+					t.Errorf("unexpected read result: %q %v", content, err)
+					return
+				}
+				afs.List("")
+				afs.ListFiles("pack/a")
+				afs.Exists("pack/b.txt")
+				afs.Stat("pack")
+			}
+		}()
+	}
+	
+	// Writers: constantly swap asset packs in and out.
+	for _, mp := range []string{"pack", "pack", "extra"} {
+		writers.Add(1)
+		go func(mp string) {
+			defer writers.Done()
+			for k := 0; k < 500; k++ {
+				afs.Mount(mp, ds, true)
+				afs.SwapMount(mp, ds, false)
+				afs.Unmount(mp, true)
+			}
+		}(mp)
+	}
+	
+	writers.Wait()
+	close(stop)
+	readers.Wait()
+	
+	if afs.Exists("pack") || afs.Exists("extra") {
+		t.Error("mounts left behind after unmounting")
+	}
+}
+
+func TestConcurrentSwapMount(t *testing.T) {
+	afs := new(axis2.FileSystem)
+	a, err := zip.NewRawDir(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := zip.NewRawDir(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	afs.Mount("base", a, true)
+	
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for k := 0; k < 500; k++ {
+				if i%2 == 0 {
+					afs.SwapMount("base", b, true)
+				} else {
+					afs.SwapMount("base", a, true)
+				}
+				if !afs.IsDir("base/a") {
+					t.Error("base/a vanished during a swap")
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
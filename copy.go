@@ -0,0 +1,219 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package axis2
+
+import "io"
+import "strings"
+
+// Copy copies the File at srcpath (on the read half) to dstpath (on the write half), replacing anything already there.
+// 
+// The contents are streamed, so even very large files are fine. If the source File implements Stater and the new
+// File implements MetaSetter the permission bits and modification time are copied as well.
+// 
+// Copying a path to itself only does something if the item is not already in the write half, in which case it is
+// copied up from the read half.
+func (fs *FileSystem) Copy(srcpath, dstpath string) error {
+	defer fs.hold()()
+	
+	sdirs := validatePath(srcpath)
+	if sdirs == nil {
+		return &Error{Path: srcpath, Typ: ErrBadPath}
+	}
+	ddirs := validatePath(dstpath)
+	if ddirs == nil {
+		return &Error{Path: dstpath, Typ: ErrBadPath}
+	}
+	if strings.Join(sdirs, "/") == strings.Join(ddirs, "/") && len(fs.lookup(ddirs, CreateNone, false)) != 0 {
+		return nil
+	}
+	
+	ds, err := fs.GetDSAt(srcpath, false, true)
+	if err != nil {
+		return err
+	}
+	from, ok := ds.(File)
+	if !ok {
+		return &Error{Path: srcpath, Typ: ErrIsDir}
+	}
+	
+	ds, err = fs.GetDSAt(dstpath, true, false)
+	if err != nil {
+		return err
+	}
+	to, ok := ds.(File)
+	if !ok {
+		return &Error{Path: dstpath, Typ: ErrIsDir}
+	}
+	
+	if err := copyFile(from, to); err != nil {
+		return wrapError(err, dstpath)
+	}
+	return wrapError(copyMeta(from, to), dstpath)
+}
+
+// CopyTree recursively copies the item at srcpath (on the read half) to dstpath (on the write half). Any Files that
+// already exist are replaced, but nothing is deleted. If srcpath is a File this is the same as Copy.
+// 
+// CopyTree does not stop at the first failure, instead it copies everything it can and then returns a MultiError
+// listing everything that went wrong (or nil if nothing did).
+func (fs *FileSystem) CopyTree(srcpath, dstpath string) error {
+	defer fs.hold()()
+	
+	sdirs := validatePath(srcpath)
+	if sdirs == nil {
+		return &Error{Path: srcpath, Typ: ErrBadPath}
+	}
+	ddirs := validatePath(dstpath)
+	if ddirs == nil {
+		return &Error{Path: dstpath, Typ: ErrBadPath}
+	}
+	srcpath, dstpath = strings.Join(sdirs, "/"), strings.Join(ddirs, "/")
+	if len(ddirs) > len(sdirs) && strings.Join(ddirs[:len(sdirs)], "/") == srcpath {
+		// Copying a directory inside itself would never end.
+		return &Error{Path: dstpath, Typ: ErrBadAction}
+	}
+	
+	var errs MultiError
+	var dirs []string
+	err := fs.Walk(srcpath, func(path string, info *FileInfo, err error) error {
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		
+		dst := joinPath(dstpath, strings.TrimPrefix(strings.TrimPrefix(path, srcpath), "/"))
+		if info.IsDir {
+			// Not all DataSources can create empty directories, but they will all create directories as needed
+			// when the Files in them are copied.
+			err := fs.MkdirAll(dst)
+			if e, ok := err.(*Error); ok && e.Typ != ErrUnsupported {
+				errs = append(errs, err)
+			}
+			dirs = append(dirs, path, dst)
+			return nil
+		}
+		if err := fs.Copy(path, dst); err != nil {
+			errs = append(errs, err)
+		}
+		return nil
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	
+	// Directory metadata is copied last (deepest first), so that copying the contents can't change the
+	// modification time, and read-only directories don't get in the way.
+	for i := len(dirs) - 2; i >= 0; i -= 2 {
+		from, err := fs.GetDSAt(dirs[i], false, true)
+		if err != nil {
+			continue
+		}
+		to, err := fs.GetDSAt(dirs[i+1], false, false)
+		if err != nil {
+			continue
+		}
+		if err := copyMeta(from, to); err != nil {
+			errs = append(errs, wrapError(err, dirs[i+1]))
+		}
+	}
+	
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// copyMeta copies the permission bits and modification time of one item to another, if both support it.
+func copyMeta(from, to DataSource) error {
+	s, ok := from.(Stater)
+	if !ok {
+		return nil
+	}
+	m, ok := to.(MetaSetter)
+	if !ok {
+		return nil
+	}
+	
+	info, err := s.Stat()
+	if err != nil {
+		return nil
+	}
+	return m.SetMeta(info.Mode().Perm(), info.ModTime())
+}
+
+// copyDS recursively copies the item ds to the child id of dir, creating it as needed.
+func copyDS(ds DataSource, dir Dir, id string) error {
+	if d, ok := ds.(Dir); ok {
+		// Create the directory explicitly if possible, so empty directories are copied.
+		if m, ok := dir.(DirMaker); ok && dir.Child(id, CreateNone) == nil {
+			if err := m.Mkdir(id); err != nil {
+				return err
+			}
+		}
+		
+		cd, ok := dir.Child(id, CreateDir).(Dir)
+		if !ok {
+			return NewError(ErrNotDir)
+		}
+		
+		for _, name := range d.List() {
+			child := d.Child(name, CreateNone)
+			if child == nil {
+				continue
+			}
+			if err := copyDS(child, cd, name); err != nil {
+				return err
+			}
+		}
+		return copyMeta(ds, cd)
+	}
+	
+	cf, ok := dir.Child(id, CreateFile).(File)
+	if !ok {
+		return NewError(ErrIsDir)
+	}
+	if err := copyFile(ds.(File), cf); err != nil {
+		return err
+	}
+	return copyMeta(ds, cf)
+}
+
+// copyFile copies the contents of one File to another.
+func copyFile(from, to File) error {
+	r, err := from.Read()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	
+	w, err := to.Write()
+	if err != nil {
+		return err
+	}
+	
+	_, err = io.Copy(w, r)
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
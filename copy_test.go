@@ -0,0 +1,150 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package axis2_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	
+	"github.com/milochristiansen/axis2"
+	"github.com/milochristiansen/axis2/sources"
+	"github.com/milochristiansen/axis2/sources/mem"
+	axiszip "github.com/milochristiansen/axis2/sources/zip"
+)
+
+func TestCopyMeta(t *testing.T) {
+	afs := new(axis2.FileSystem)
+	afs.Mount("src", mem.NewDir(), true)
+	afs.Mount("dst", mem.NewDir(), true)
+	if err := afs.WriteAll("src/d/a.txt", []byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	
+	mod := time.Date(2016, time.October, 28, 12, 0, 0, 0, time.UTC)
+	for _, path := range []string{"src/d/a.txt", "src/d"} {
+		ds, err := afs.GetDSAt(path, false, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ds.(axis2.MetaSetter).SetMeta(0750, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	
+	if err := afs.Copy("src/d/a.txt", "dst/a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := afs.CopyTree("src/d", "dst/d"); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"dst/a.txt", "dst/d/a.txt", "dst/d"} {
+		info, err := afs.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode.Perm() != 0750 || !info.ModTime.Equal(mod) {
+			t.Errorf("%v: metadata not copied: %v %v", path, info.Mode, info.ModTime)
+		}
+	}
+	if content, _ := afs.ReadAll("dst/d/a.txt"); string(content) != "a" {
+		t.Errorf("unexpected contents: %q", content)
+	}
+	
+	if err := afs.Copy("src/d", "dst/e"); !errors.Is(err, axis2.ErrIsDirSentinel) {
+		t.Errorf("copy of directory: %v", err)
+	}
+	if err := afs.CopyTree("src", "src/d/src"); !errors.Is(err, axis2.ErrBadActionSentinel) {
+		t.Errorf("copy into itself: %v", err)
+	}
+}
+
+func TestCopyTreeErrors(t *testing.T) {
+	afs := new(axis2.FileSystem)
+	src := memDir(t, map[string]string{"a.txt": "a", "bad.txt": "bad", "d/bad.txt": "bad", "d/z.txt": "z"})
+	afs.Mount("src", brokenDir{src}, false)
+	afs.Mount("dst", mem.NewDir(), true)
+	
+	err := afs.CopyTree("src", "dst")
+	var errs axis2.MultiError
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("expected a MultiError with two errors, got: %v", err)
+	}
+	var aerr *axis2.Error
+	if !errors.As(errs[0], &aerr) || aerr.Path != "dst/bad.txt" {
+		t.Errorf("unexpected first error: %v", errs[0])
+	}
+	
+	// Everything else is still copied.
+	for _, name := range []string{"dst/a.txt", "dst/d/z.txt"} {
+		if !afs.Exists(name) {
+			t.Errorf("%v was not copied", name)
+		}
+	}
+}
+
+func TestCopyTreeZip(t *testing.T) {
+	// archive/zip does not write directory entries unless asked to.
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	for _, name := range []string{"a/x.txt", "a/b/y.txt", "z.txt"} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(name))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	src, err := axiszip.NewRawDir(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	
+	dir := t.TempDir()
+	afs := new(axis2.FileSystem)
+	afs.Mount("src", src, false)
+	afs.Mount("dst", sources.NewOSDir(dir), true)
+	if err := afs.CopyTree("src", "dst"); err != nil {
+		t.Fatal(err)
+	}
+	
+	// The copied directories must still be writable.
+	for _, path := range []string{"", "a", "a/b"} {
+		info, err := os.Stat(filepath.Join(dir, path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm()&0200 == 0 {
+			t.Errorf("%q: copied directory is not writable: %v", path, info.Mode())
+		}
+	}
+	if err := afs.RemoveAll("dst/a"); err != nil {
+		t.Errorf("copied directory cannot be removed: %v", err)
+	}
+}
@@ -22,7 +22,11 @@ misrepresented as being the original software.
 
 package axis2
 
+import "errors"
+import "fmt"
+import iofs "io/fs"
 import "os"
+import "strings"
 
 type ErrTyp int
 const (
@@ -32,7 +36,7 @@ const (
 	// The requested action could not be carried out because the item is read-only.
 	ErrReadOnly
 	
-	// The action cannot be done with the item (for example trying to write a directory).
+	// The action cannot be done with the item (for example trying to move a directory inside itself).
 	ErrBadAction
 	
 	// The given path is not absolute or it contains invalid characters.
@@ -40,8 +44,61 @@ const (
 	
 	// An error from an external library, with an attached AXIS path.
 	ErrRaw
+	
+	// Something already exists at the path.
+	ErrExists
+	
+	// The action is not supported by the DataSource(s) the path points to.
+	ErrUnsupported
+	
+	// The directory could not be deleted because it is not empty.
+	ErrNotEmpty
+	
+	// The OS (or some other external system) denied access to the item.
+	ErrPermission
+	
+	// A directory was required, but the item (or one of its parents) is a file.
+	ErrNotDir
+	
+	// A file was required, but the item is a directory.
+	ErrIsDir
 )
 
+// Sentinel errors for use with errors.Is. An error matches one of these if it is (or wraps) an *Error with the same Typ,
+// for example errors.Is(err, ErrNotFoundSentinel) is the same as checking for an *Error with Typ == ErrNotFound.
+// 
+// Errors also match the equivalent io/fs error (see Error.Is), so errors.Is(err, fs.ErrNotExist) works too.
+var (
+	ErrNotFoundSentinel    error = &Error{Typ: ErrNotFound}
+	ErrReadOnlySentinel    error = &Error{Typ: ErrReadOnly}
+	ErrBadActionSentinel   error = &Error{Typ: ErrBadAction}
+	ErrBadPathSentinel     error = &Error{Typ: ErrBadPath}
+	ErrExistsSentinel      error = &Error{Typ: ErrExists}
+	ErrUnsupportedSentinel error = &Error{Typ: ErrUnsupported}
+	ErrNotEmptySentinel    error = &Error{Typ: ErrNotEmpty}
+	ErrPermissionSentinel  error = &Error{Typ: ErrPermission}
+	ErrNotDirSentinel      error = &Error{Typ: ErrNotDir}
+	ErrIsDirSentinel       error = &Error{Typ: ErrIsDir}
+)
+
+// ioErr returns the standard library error that matches the error type, or nil if there isn't one.
+func (typ ErrTyp) ioErr() error {
+	switch typ {
+	case ErrNotFound:
+		return iofs.ErrNotExist
+	case ErrReadOnly, ErrPermission:
+		return iofs.ErrPermission
+	case ErrBadAction, ErrBadPath, ErrNotDir, ErrIsDir:
+		return iofs.ErrInvalid
+	case ErrExists:
+		return iofs.ErrExist
+	case ErrUnsupported:
+		return errors.ErrUnsupported
+	default:
+		return nil
+	}
+}
+
 // NewError creates a new AXIS Error with the given type.
 // Error path information is automatically filled in by the API just before it is returned to the user.
 func NewError(typ ErrTyp) error {
@@ -57,25 +114,24 @@ func wrapError(err error, path string) error {
 		return nil
 	}
 	if e, ok := err.(*Error); ok {
-		e.Path = path
-		return e
+		// Copy the error, it may be one of the sentinels (or otherwise shared).
+		rtn := *e
+		rtn.Path = path
+		return &rtn
 	}
 	
 	// Don't wrap os.PathError values directly (we don't want the error message to include the OS path).
-	if e, ok := err.(*os.PathError); ok {
-		// Convert file not found directly to the equivalent AXIS error
-		if e.Err == os.ErrNotExist {
-			return &Error{
-				Path: path,
-				Typ: ErrNotFound,
-			}
-		}
-		
-		// Unwrap, then rewrap any other PathErrors
+	var pe *os.PathError
+	if errors.As(err, &pe) {
+		err = pe.Err
+	}
+	
+	// Convert errors with an AXIS equivalent directly. This uses errors.Is so that syscall.ENOENT and the like are
+	// caught as well.
+	if typ, ok := classify(err); ok {
 		return &Error{
 			Path: path,
-			Typ: ErrRaw,
-			Err: e.Err,
+			Typ: typ,
 		}
 	}
 	
@@ -87,6 +143,26 @@ func wrapError(err error, path string) error {
 	}
 }
 
+// classify returns the error type for errors from the os package (and syscall errors) that have an AXIS equivalent.
+func classify(err error) (ErrTyp, bool) {
+	// This must come first, since ENOTEMPTY also matches os.ErrExist.
+	if typ, ok := classifyErrno(err); ok {
+		return typ, true
+	}
+	
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return ErrNotFound, true
+	case errors.Is(err, os.ErrExist):
+		return ErrExists, true
+	case errors.Is(err, os.ErrPermission):
+		return ErrPermission, true
+	case errors.Is(err, errors.ErrUnsupported):
+		return ErrUnsupported, true
+	}
+	return 0, false
+}
+
 // Error wraps a path and an error type, together with an error from an external library if applicable.
 // Errors returned by API functions will be of this type.
 // 
@@ -101,13 +177,23 @@ type Error struct {
 	Typ ErrTyp
 	
 	// If Typ == ErrRaw this will contain an error value originating from a specific implementation of File or Dir.
+	// For other types this may contain the errors that caused this one, for example an ErrNotFound error may have a
+	// MultiError listing the DataSources that failed while looking for the item.
 	Err error
 }
 
 // Error prints the path associated with the error prefixed by a short string explanation of the error type.
 // 
-// Raw (wrapped) errors simply have "AXIS path: <path>" tacked onto the output of their own Error function.
+// Raw (wrapped) errors simply have "AXIS path: <path>" tacked onto the output of their own Error function. Other errors
+// that wrap an error have its message added on the following line(s).
 func (err *Error) Error() string {
+	if err.Typ != ErrRaw && err.Err != nil {
+		return err.message() + "\n" + err.Err.Error()
+	}
+	return err.message()
+}
+
+func (err *Error) message() string {
 	switch err.Typ {
 	case ErrNotFound:
 		return "No item found at path: " + err.Path
@@ -119,7 +205,94 @@ func (err *Error) Error() string {
 		return "Path is invalid: " + err.Path
 	case ErrRaw:
 		return err.Err.Error() + " AXIS path: " + err.Path
+	case ErrExists:
+		return "Item already exists at path: " + err.Path
+	case ErrUnsupported:
+		return "Action not supported for item at path: " + err.Path
+	case ErrNotEmpty:
+		return "Directory not empty at path: " + err.Path
+	case ErrPermission:
+		return "Permission denied for item at path: " + err.Path
+	case ErrNotDir:
+		return "Not a directory at path: " + err.Path
+	case ErrIsDir:
+		return "Item is a directory at path: " + err.Path
 	default:
 		return "Invalid error code: " + err.Path
 	}
 }
+
+// Unwrap returns the wrapped error from an external library, if any.
+func (err *Error) Unwrap() error {
+	return err.Err
+}
+
+// Is reports whether the error matches target. An Error matches any other *Error with the same Typ (such as the
+// sentinel errors), as well as the matching io/fs error:
+// 
+//	ErrNotFound    -> fs.ErrNotExist
+//	ErrReadOnly    -> fs.ErrPermission
+//	ErrPermission  -> fs.ErrPermission
+//	ErrBadAction   -> fs.ErrInvalid
+//	ErrBadPath     -> fs.ErrInvalid
+//	ErrNotDir      -> fs.ErrInvalid
+//	ErrIsDir       -> fs.ErrInvalid
+//	ErrExists      -> fs.ErrExist
+//	ErrUnsupported -> errors.ErrUnsupported
+// 
+// Errors of type ErrRaw only match through the error they wrap, and errors of type ErrNotEmpty only match other *Errors.
+func (err *Error) Is(target error) bool {
+	if e, ok := target.(*Error); ok {
+		return err.Typ != ErrRaw && e.Typ == err.Typ
+	}
+	return target != nil && target == err.Typ.ioErr()
+}
+
+// SourceError records the failure of a single mounted DataSource, so that it is possible to tell which of several
+// DataSources mounted at the same location caused a problem.
+type SourceError struct {
+	// The mount point and the mounted DataSource (not the item that failed).
+	MountPoint string
+	Source     DataSource
+	
+	// The error returned by the DataSource, with an AXIS path.
+	Err error
+}
+
+func newSourceError(src *source, dirs []string, err error) *SourceError {
+	return &SourceError{
+		MountPoint: strings.Join(src.mp, "/"),
+		Source: src.ds,
+		Err: wrapError(err, strings.Join(dirs, "/")),
+	}
+}
+
+// Error prints the wrapped error, followed by the mount point and the type of the DataSource.
+func (err *SourceError) Error() string {
+	return fmt.Sprintf("%v (from %T mounted at %q)", err.Err, err.Source, err.MountPoint)
+}
+
+// Unwrap returns the wrapped error.
+func (err *SourceError) Unwrap() error {
+	return err.Err
+}
+
+// MultiError is a list of errors, returned by operations that keep going after a failure (CopyTree, for example).
+// 
+// Like the errors returned by errors.Join, a MultiError has an Unwrap method that returns every error in the list,
+// so errors.Is and errors.As will check each of them.
+type MultiError []error
+
+// Error prints each error in the list on a line of its own.
+func (errs MultiError) Error() string {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the errors in the list.
+func (errs MultiError) Unwrap() []error {
+	return errs
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package axis2_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"testing/fstest"
+	
+	"github.com/milochristiansen/axis2"
+	"github.com/milochristiansen/axis2/sources"
+	axisfs "github.com/milochristiansen/axis2/sources/iofs"
+	"github.com/milochristiansen/axis2/sources/mem"
+	"github.com/milochristiansen/axis2/sources/zip"
+)
+
+// enoentDir returns an enoentFile for every child. Reading an enoentFile fails with ENOENT, without a *os.PathError.
+type enoentDir struct {
+	*mem.Dir
+}
+
+func (dir enoentDir) Child(id string, create int) axis2.DataSource {
+	return enoentFile{}
+}
+
+type enoentFile struct{}
+
+func (enoentFile) Size() int64 {
+	return 0
+}
+
+func (enoentFile) Read() (io.ReadCloser, error) {
+	return nil, fmt.Errorf("open: %w", syscall.ENOENT)
+}
+
+func (enoentFile) Write() (io.WriteCloser, error) {
+	return nil, axis2.ErrReadOnlySentinel
+}
+
+func (enoentFile) Append() (io.WriteCloser, error) {
+	return nil, axis2.ErrReadOnlySentinel
+}
+
+func TestErrorIs(t *testing.T) {
+	afs := new(axis2.FileSystem)
+	afs.Mount("os", sources.NewOSDir(t.TempDir()), true)
+	afs.Mount("enoent", enoentDir{mem.NewDir()}, true)
+	
+	_, err := afs.Read("os/missing.txt")
+	if !errors.Is(err, fs.ErrNotExist) || !errors.Is(err, axis2.ErrNotFoundSentinel) {
+		t.Errorf("missing file: unexpected error: %v", err)
+	}
+	if errors.Is(err, axis2.ErrReadOnlySentinel) || errors.Is(err, fs.ErrPermission) {
+		t.Errorf("missing file: error matches the wrong type: %v", err)
+	}
+	
+	_, err = afs.Read("enoent/x.txt")
+	if !errors.Is(err, axis2.ErrNotFoundSentinel) {
+		t.Errorf("wrapped ENOENT: unexpected error: %v", err)
+	}
+	
+	// Sentinels returned by a DataSource must not be modified when the path is filled in.
+	err = afs.WriteAll("enoent/x.txt", nil)
+	var e *axis2.Error
+	if !errors.As(err, &e) || e.Path != "enoent/x.txt" || !errors.Is(err, os.ErrPermission) {
+		t.Errorf("read-only file: unexpected error: %v", err)
+	}
+	if e == axis2.ErrReadOnlySentinel || axis2.ErrReadOnlySentinel.(*axis2.Error).Path != "" {
+		t.Error("sentinel error was modified")
+	}
+	
+	if err := afs.Mkdir("os/dir"); err != nil {
+		t.Fatal(err)
+	}
+	err = afs.Mkdir("os/dir")
+	if !errors.Is(err, fs.ErrExist) || !errors.Is(err, axis2.ErrExistsSentinel) {
+		t.Errorf("existing dir: unexpected error: %v", err)
+	}
+}
+
+func TestErrorTypes(t *testing.T) {
+	for name, ds := range map[string]axis2.Dir{"os": sources.NewOSDir(t.TempDir()), "mem": mem.NewDir()} {
+		afs := new(axis2.FileSystem)
+		afs.Mount("", ds, true)
+		if err := afs.WriteAll("dir/file.txt", nil); err != nil {
+			t.Fatal(err)
+		}
+		
+		_, rerr := afs.Read("dir")
+		checks := []struct {
+			err  error
+			want error
+		}{
+			{afs.Delete("dir"), axis2.ErrNotEmptySentinel},
+			{afs.MkdirAll("dir/file.txt/sub"), axis2.ErrNotDirSentinel},
+			{afs.WriteAll("dir", nil), axis2.ErrIsDirSentinel},
+			{rerr, axis2.ErrIsDirSentinel},
+		}
+		
+		for i, c := range checks {
+			if !errors.Is(c.err, c.want) {
+				t.Errorf("%v: check %v: got %v, want %v", name, i, c.err, c.want)
+			}
+		}
+		
+		// A File in the parent path is reported as such, not just wrapped in an ErrNotFound.
+		_, rerr = afs.Read("dir/file.txt/x")
+		for i, err := range []error{rerr, afs.WriteAll("dir/file.txt/x", nil)} {
+			var e *axis2.Error
+			if !errors.As(err, &e) || e.Typ != axis2.ErrNotDir || errors.Is(err, axis2.ErrNotFoundSentinel) {
+				t.Errorf("%v: file in parent path %v: unexpected error: %v", name, i, err)
+			}
+		}
+	}
+}
+
+// deniedFS is an fs.FS that denies access to everything.
+type deniedFS struct{}
+
+func (deniedFS) Open(name string) (fs.File, error) {
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+}
+
+func TestSourceErrors(t *testing.T) {
+	defaults := fstest.MapFS{
+		"configs/game.json": {Data: []byte("default")},
+	}
+	
+	afs := new(axis2.FileSystem)
+	afs.Mount("", axisfs.NewDir(defaults), false)
+	afs.Mount("", axisfs.NewDir(deniedFS{}), false)
+	afs.Mount("", mem.NewDir(), true)
+	afs.AutoMount(".zip", zip.OpenFile)
+	
+	// The item is found in the last DataSource, so there is no error, but Diagnose explains why.
+	if _, err := afs.ReadAll("configs/game.json"); err != nil {
+		t.Fatal(err)
+	}
+	err := afs.Diagnose("configs/game.json", true)
+	var serr *axis2.SourceError
+	if !errors.As(err, &serr) || !errors.Is(err, fs.ErrPermission) {
+		t.Fatalf("unexpected Diagnose result: %v", err)
+	}
+	if _, ok := serr.Source.(axis2.Dir); !ok || serr.MountPoint != "" {
+		t.Errorf("unexpected SourceError: %#v", serr)
+	}
+	if err := afs.Diagnose("configs/game.json", false); err != nil {
+		t.Errorf("unexpected Diagnose result for the write half: %v", err)
+	}
+	
+	// When nothing is found the failures are attached to the ErrNotFound error.
+	_, err = afs.Read("configs/missing.json")
+	if !errors.Is(err, axis2.ErrNotFoundSentinel) || !errors.Is(err, fs.ErrPermission) || !errors.As(err, &serr) {
+		t.Errorf("unexpected error for missing file: %v", err)
+	}
+	
+	// Archives that fail to open are reported as well.
+	if err := afs.WriteAll("bad.zip", []byte("not a zip file")); err != nil {
+		t.Fatal(err)
+	}
+	_, err = afs.Read("bad.zip/x.txt")
+	if !errors.Is(err, axis2.ErrNotFoundSentinel) || !strings.Contains(err.Error(), "not a valid zip file") {
+		t.Errorf("unexpected error for broken archive: %v", err)
+	}
+}
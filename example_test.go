@@ -24,11 +24,14 @@ package axis2_test
 
 import (
 	"fmt"
+	"os"
 	"sort"
 	"io/ioutil"
+	iofs "io/fs"
 	"encoding/base64"
 	
 	"github.com/milochristiansen/axis2"
+	"github.com/milochristiansen/axis2/sources/mem"
 	"github.com/milochristiansen/axis2/sources/zip"
 )
 
@@ -140,6 +143,104 @@ func printTree(fs *axis2.FileSystem, p, d string) {
 }
 
 
+func ExampleFileSystem_Walk() {
+	fs := new(axis2.FileSystem)
+	
+	ds, err := zip.NewRawDir(data)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	
+	// Mount the same zip file twice on one location and a third time deeper in the tree. The first two will be
+	// merged, and the third will show up as a mount point subset ("mods") inside the other two.
+	fs.Mount("base", ds, false)
+	fs.Mount("base", ds, false)
+	fs.Mount("base/mods/extra", ds, false)
+	
+	fs.Walk("base", func(path string, info *axis2.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		
+		// Skip the contents of the "a" directories.
+		if info.IsDir && info.Name == "a" {
+			fmt.Println(path + "/ (skipped)")
+			return iofs.SkipDir
+		}
+		if info.IsDir {
+			path += "/"
+		}
+		fmt.Println(path)
+		return nil
+	})
+	
+	// Output:
+	// base/
+	// base/a/ (skipped)
+	// base/b.txt
+	// base/c.txt
+	// base/mods/
+	// base/mods/extra/
+	// base/mods/extra/a/ (skipped)
+	// base/mods/extra/b.txt
+	// base/mods/extra/c.txt
+}
+
+
+func ExampleFileSystem_Glob() {
+	fs := new(axis2.FileSystem)
+	
+	ds, err := zip.NewRawDir(data)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fs.Mount("mods/one", ds, false)
+	fs.Mount("mods/two", ds, false)
+	fs.Mount("mods/two", ds, false)
+	
+	for _, pattern := range []string{"mods/*/a/[xy].txt", "**/c.txt"} {
+		matches, err := fs.Glob(pattern)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println(pattern, matches)
+	}
+	
+	// Output:
+	// mods/*/a/[xy].txt [mods/one/a/x.txt mods/one/a/y.txt mods/two/a/x.txt mods/two/a/y.txt]
+	// **/c.txt [mods/one/c.txt mods/two/c.txt]
+}
+
+
+func ExampleFileSystem_Dump() {
+	fs := new(axis2.FileSystem)
+	
+	ds, err := zip.NewRawDir(data)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fs.Mount("mods/base", ds, false)
+	fs.Mount("", mem.NewDir(), true)
+	fs.Mount("mods/base", mem.NewDir(), true)
+	fs.Mount("saves", mem.NewDir(), true)
+	
+	fs.Dump(os.Stdout)
+	
+	// Output:
+	// /
+	// 	*mem.Dir (read #1, write #0)
+	// 	mods/
+	// 		base/
+	// 			*zip.zdir (read #0): (zip)
+	// 			*mem.Dir (read #2, write #1)
+	// 	saves/
+	// 		*mem.Dir (read #3, write #2)
+}
+
 // After init runs data will contain a zip file with the following contents:
 //	a/x.txt
 //	a/y.txt
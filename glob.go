@@ -0,0 +1,124 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package axis2
+
+import ospath "path"
+import "sort"
+import "strings"
+
+// Glob returns the paths of all the items that match the given pattern, sorted lexically.
+// 
+// Patterns are slash separated paths (following the same rules as any other AXIS path) where each element may
+// use the syntax of path.Match: "*" matches any run of characters, "?" matches any single character, "[...]" is a
+// character class, and "\" escapes the next character. Additionally an element consisting of just "**" matches
+// zero or more path elements, so "mods/**/*.json" matches every JSON file anywhere under "mods".
+// 
+// Matching is done against the merged view used by List, so items from multiplexed DataSources and mount point
+// subsets are included, and each matching path is returned exactly once.
+// 
+// The only possible error is an ErrBadPath for a malformed pattern. If nothing matches the result is nil.
+func (fs *FileSystem) Glob(pattern string) ([]string, error) {
+	pats := parseGlob(pattern)
+	if pats == nil {
+		return nil, &Error{Path: pattern, Typ: ErrBadPath}
+	}
+	
+	have := map[string]bool{}
+	var rtn []string
+	fs.glob("", pats, have, &rtn)
+	sort.Strings(rtn)
+	return rtn, nil
+}
+
+func (fs *FileSystem) glob(path string, pats []string, have map[string]bool, rtn *[]string) {
+	if len(pats) == 0 {
+		if !have[path] {
+			have[path] = true
+			*rtn = append(*rtn, path)
+		}
+		return
+	}
+	
+	pat := pats[0]
+	switch {
+	case pat == "**":
+		// Match zero elements, then one or more.
+		fs.glob(path, pats[1:], have, rtn)
+		for _, name := range fs.List(path) {
+			fs.glob(joinPath(path, name), pats, have, rtn)
+		}
+	case !hasMeta(pat):
+		// Literal elements don't need a directory listing.
+		name := unescapeGlob(pat)
+		if fs.Exists(joinPath(path, name)) {
+			fs.glob(joinPath(path, name), pats[1:], have, rtn)
+		}
+	default:
+		for _, name := range fs.List(path) {
+			if ok, _ := ospath.Match(pat, name); ok {
+				fs.glob(joinPath(path, name), pats[1:], have, rtn)
+			}
+		}
+	}
+}
+
+// parseGlob splits a glob pattern into its elements, returning nil if the pattern is invalid.
+// 
+// validatePath can't be used, because it (rightly) rejects the special characters used in patterns.
+func parseGlob(pattern string) []string {
+	pats := []string{}
+	for _, pat := range strings.Split(pattern, "/") {
+		if pat == "" {
+			continue
+		}
+		if pat == "." || pat == ".." || strings.ContainsAny(pat, "<>|:\"") {
+			return nil
+		}
+		if _, err := ospath.Match(pat, ""); err != nil {
+			return nil
+		}
+		pats = append(pats, pat)
+	}
+	return pats
+}
+
+// hasMeta returns true if the given pattern element contains any special characters.
+func hasMeta(pat string) bool {
+	return strings.ContainsAny(pat, "*?[")
+}
+
+// unescapeGlob removes the escapes from a pattern element that contains no other special characters.
+func unescapeGlob(pat string) string {
+	return strings.Replace(pat, "\\", "", -1)
+}
+
+// joinPath joins two (already clean) AXIS paths.
+func joinPath(a, b string) string {
+	if a == "" {
+		return b
+	}
+	if b == "" {
+		return a
+	}
+	return a + "/" + b
+}
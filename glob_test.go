@@ -0,0 +1,100 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+
+package axis2_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+	
+	"github.com/milochristiansen/axis2"
+	axisfs "github.com/milochristiansen/axis2/sources/iofs"
+)
+
+func TestGlob(t *testing.T) {
+	afs := walkFS()
+	afs.Mount("", axisfs.NewDir(fstest.MapFS{
+		"[x].txt": {},
+		"x.txt":   {},
+		"y.json":  {},
+	}), false)
+	
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"c.txt", "c.txt"},
+		{"*", "[x].txt a c.txt d x.txt y.json"},
+		{"a/?.txt", "a/w.txt a/x.txt a/y.txt"},
+		{"a/[wx].txt", "a/w.txt a/x.txt"},
+		{"a/[^wx].txt", "a/y.txt"},
+		{"a/[a-x].txt", "a/w.txt a/x.txt"},
+		{"[x].txt", "x.txt"},
+		{"\\[x].txt", "[x].txt"},
+		{"\\[x\\].txt", "[x].txt"},
+		{"\\c.txt", "c.txt"},
+		{"*/*.txt", "a/w.txt a/x.txt a/y.txt d/e.txt"},
+		{"**/e.txt", "d/e.txt"},
+		{"**/*.json", "y.json"},
+		{"d/**", "d d/e.txt d/f d/f/g.txt d/f/h.txt"},
+		{"d/f/*", "d/f/g.txt d/f/h.txt"},
+		{"**/f", "d/f"},
+		{"missing/*", ""},
+		{"a/??", ""},
+	}
+	for _, test := range tests {
+		matches, err := afs.Glob(test.pattern)
+		if err != nil {
+			t.Errorf("%v: %v", test.pattern, err)
+			continue
+		}
+		if got := strings.Join(matches, " "); got != test.want {
+			t.Errorf("%v: unexpected matches:\n\tgot:  %q\n\twant: %q", test.pattern, got, test.want)
+		}
+	}
+	
+	// Every path is returned once, even though "c.txt" and "d/e.txt" are provided by two DataSources and "d/f" is
+	// both a directory and a mount point.
+	matches, _ := afs.Glob("**")
+	seen := map[string]bool{}
+	for _, match := range matches {
+		if seen[match] {
+			t.Errorf("%v: returned more than once", match)
+		}
+		seen[match] = true
+	}
+	if len(seen) != 16 {
+		t.Errorf("unexpected result for **: %q", matches)
+	}
+}
+
+func TestGlobBadPattern(t *testing.T) {
+	afs := walkFS()
+	for _, pattern := range []string{"a/[x", "a/x\\", "../a", "a/./*", "a:*"} {
+		if matches, err := afs.Glob(pattern); !errors.Is(err, axis2.ErrBadPathSentinel) || matches != nil {
+			t.Errorf("%q: unexpected result: %q (%v)", pattern, matches, err)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package axis2
+
+import "io"
+import iofs "io/fs"
+import ospath "path"
+import "sort"
+import "time"
+
+// IOFS adapts a FileSystem to the standard library io/fs interfaces, so that it may be handed to anything that
+// takes an fs.FS (template.ParseFS, http.FS, fs.WalkDir, fs.Glob, etc).
+// 
+// IOFS implements fs.FS, fs.ReadDirFS, fs.StatFS, fs.ReadFileFS, and fs.SubFS. Mount point subsets are presented as
+// ordinary (read-only) directories. Names passed to an IOFS must follow the io/fs rules rather than the (more forgiving)
+// AXIS rules, so "." is the root, and leading or trailing slashes are not allowed.
+// 
+// AXIS errors are converted to the matching io/fs errors (ErrNotFound becomes fs.ErrNotExist, ErrBadPath becomes
+// fs.ErrInvalid, etc) and wrapped in a *fs.PathError, as io/fs requires.
+type IOFS struct {
+	fs   *FileSystem
+	root string
+}
+
+// NewIOFS returns an io/fs view of the given FileSystem.
+func NewIOFS(fs *FileSystem) *IOFS {
+	return &IOFS{fs: fs}
+}
+
+// Open opens the named file or directory. Directories implement fs.ReadDirFile.
+func (afs *IOFS) Open(name string) (iofs.File, error) {
+	path, err := afs.path("open", name)
+	if err != nil {
+		return nil, err
+	}
+	
+	info, err := afs.stat("open", name, path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return &ioDir{afs: afs, name: name, path: path, info: info}, nil
+	}
+	
+	rc, err := afs.fs.Read(path)
+	if err != nil {
+		return nil, ioError("open", name, err)
+	}
+	return &ioFile{rc: rc, name: name, info: info}, nil
+}
+
+// Stat returns a fs.FileInfo describing the named item.
+func (afs *IOFS) Stat(name string) (iofs.FileInfo, error) {
+	path, err := afs.path("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	
+	info, err := afs.stat("stat", name, path)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// ReadDir reads the named directory and returns a list of directory entries sorted by filename.
+func (afs *IOFS) ReadDir(name string) ([]iofs.DirEntry, error) {
+	path, err := afs.path("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	
+	info, err := afs.stat("readdir", name, path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, &iofs.PathError{Op: "readdir", Path: name, Err: iofs.ErrInvalid}
+	}
+	return afs.readDir(path), nil
+}
+
+// ReadFile reads the named file and returns its contents.
+func (afs *IOFS) ReadFile(name string) ([]byte, error) {
+	path, err := afs.path("read", name)
+	if err != nil {
+		return nil, err
+	}
+	
+	content, err := afs.fs.ReadAll(path)
+	if err != nil {
+		return nil, ioError("read", name, err)
+	}
+	return content, nil
+}
+
+// Sub returns an IOFS rooted at the given directory.
+func (afs *IOFS) Sub(dir string) (iofs.FS, error) {
+	path, err := afs.path("sub", dir)
+	if err != nil {
+		return nil, err
+	}
+	if dir == "." {
+		return afs, nil
+	}
+	return &IOFS{fs: afs.fs, root: path}, nil
+}
+
+// path converts an io/fs name to an AXIS path (relative to the IOFS root).
+func (afs *IOFS) path(op, name string) (string, error) {
+	if !iofs.ValidPath(name) {
+		return "", &iofs.PathError{Op: op, Path: name, Err: iofs.ErrInvalid}
+	}
+	
+	if name == "." {
+		return afs.root, nil
+	}
+	if validatePath(name) == nil {
+		return "", &iofs.PathError{Op: op, Path: name, Err: iofs.ErrInvalid}
+	}
+	if afs.root == "" {
+		return name, nil
+	}
+	return afs.root + "/" + name, nil
+}
+
+func (afs *IOFS) stat(op, name, path string) (*ioInfo, error) {
+	info, err := afs.fs.Stat(path)
+	if err != nil {
+		return nil, ioError(op, name, err)
+	}
+	return newIOInfo(ospath.Base(name), info), nil
+}
+
+// readDir returns the sorted entries for the directory at the given path.
+// Items that cannot be accessed with an IOFS (for example due to invalid names) are left out.
+func (afs *IOFS) readDir(path string) []iofs.DirEntry {
+	names := afs.fs.List(path)
+	sort.Strings(names)
+	
+	rtn := make([]iofs.DirEntry, 0, len(names))
+	for i, name := range names {
+		// List may return duplicates if mount points overlap data sources.
+		if i > 0 && names[i-1] == name {
+			continue
+		}
+		if !iofs.ValidPath(name) {
+			continue
+		}
+		
+		info, err := afs.stat("readdir", name, joinPath(path, name))
+		if err != nil {
+			continue
+		}
+		rtn = append(rtn, info)
+	}
+	return rtn
+}
+
+// ioError converts an error returned by the FileSystem to a *fs.PathError, translating AXIS error types to the
+// matching io/fs errors.
+func ioError(op, name string, err error) error {
+	if e, ok := err.(*Error); ok {
+		if e.Typ == ErrRaw {
+			err = e.Err
+		} else if ioerr := e.Typ.ioErr(); ioerr != nil {
+			err = ioerr
+		}
+	}
+	return &iofs.PathError{Op: op, Path: name, Err: err}
+}
+
+// ioInfo is both the fs.FileInfo and fs.DirEntry for an item.
+type ioInfo struct {
+	name string
+	size int64
+	mode iofs.FileMode
+	mod  time.Time
+}
+
+func newIOInfo(name string, info *FileInfo) *ioInfo {
+	return &ioInfo{
+		name: name,
+		size: info.Size,
+		mode: info.Mode,
+		mod:  info.ModTime,
+	}
+}
+
+func (info *ioInfo) Name() string {
+	return info.name
+}
+
+func (info *ioInfo) Size() int64 {
+	return info.size
+}
+
+func (info *ioInfo) Mode() iofs.FileMode {
+	return info.mode
+}
+
+func (info *ioInfo) ModTime() time.Time {
+	return info.mod
+}
+
+func (info *ioInfo) IsDir() bool {
+	return info.mode.IsDir()
+}
+
+func (info *ioInfo) Sys() interface{} {
+	return nil
+}
+
+func (info *ioInfo) Type() iofs.FileMode {
+	return info.Mode().Type()
+}
+
+func (info *ioInfo) Info() (iofs.FileInfo, error) {
+	return info, nil
+}
+
+type ioFile struct {
+	rc   io.ReadCloser
+	name string
+	info *ioInfo
+}
+
+func (file *ioFile) Stat() (iofs.FileInfo, error) {
+	return file.info, nil
+}
+
+func (file *ioFile) Read(b []byte) (int, error) {
+	return file.rc.Read(b)
+}
+
+func (file *ioFile) Close() error {
+	return file.rc.Close()
+}
+
+type ioDir struct {
+	afs  *IOFS
+	name string
+	path string
+	info *ioInfo
+	
+	entries []iofs.DirEntry
+	loaded  bool
+	offset  int
+}
+
+func (dir *ioDir) Stat() (iofs.FileInfo, error) {
+	return dir.info, nil
+}
+
+func (dir *ioDir) Read([]byte) (int, error) {
+	return 0, &iofs.PathError{Op: "read", Path: dir.name, Err: iofs.ErrInvalid}
+}
+
+func (dir *ioDir) Close() error {
+	return nil
+}
+
+func (dir *ioDir) ReadDir(n int) ([]iofs.DirEntry, error) {
+	if !dir.loaded {
+		dir.entries = dir.afs.readDir(dir.path)
+		dir.loaded = true
+	}
+	
+	rest := dir.entries[dir.offset:]
+	if n <= 0 {
+		dir.offset = len(dir.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(rest) {
+		n = len(rest)
+	}
+	dir.offset += n
+	return rest[:n], nil
+}
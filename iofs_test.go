@@ -0,0 +1,121 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package axis2_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	
+	"github.com/milochristiansen/axis2"
+	"github.com/milochristiansen/axis2/sources"
+	axisfs "github.com/milochristiansen/axis2/sources/iofs"
+	"github.com/milochristiansen/axis2/sources/zip"
+)
+
+func TestIOFSZip(t *testing.T) {
+	afs := new(axis2.FileSystem)
+	ds, err := zip.NewRawDir(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	afs.Mount("mods/base", ds, false)
+	
+	err = fstest.TestFS(axis2.NewIOFS(afs), "mods/base/a/x.txt", "mods/base/a/y.txt", "mods/base/b.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIOFSOS(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a/x.txt", "a/b/y.txt", "z.txt"} {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(name), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	
+	afs := new(axis2.FileSystem)
+	afs.Mount("", sources.NewOSDir(dir), true)
+	ds, err := zip.NewRawDir(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	afs.Mount("a/zip", ds, false)
+	
+	err = fstest.TestFS(axis2.NewIOFS(afs), "a/x.txt", "a/b/y.txt", "z.txt", "a/zip/c.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	
+	_, err = fs.Stat(axis2.NewIOFS(afs), "missing.txt")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("unexpected error for missing file: %v", err)
+	}
+}
+
+func TestMountIOFS(t *testing.T) {
+	defaults := fstest.MapFS{
+		"configs/game.json": {Data: []byte("default")},
+		"configs/keys.json": {Data: []byte("keys")},
+	}
+	
+	afs := new(axis2.FileSystem)
+	afs.Mount("", sources.NewOSDir(t.TempDir()), true)
+	afs.Mount("", axisfs.NewDir(defaults), false)
+	
+	if err := afs.WriteAll("configs/game.json", []byte("user")); err != nil {
+		t.Fatal(err)
+	}
+	if err := afs.WriteAll("configs/new.json", nil); err != nil {
+		t.Fatal(err)
+	}
+	
+	for name, want := range map[string]string{"configs/game.json": "user", "configs/keys.json": "keys"} {
+		got, err := afs.ReadAll(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%v: got %q, want %q", name, got, want)
+		}
+	}
+	
+	err := fstest.TestFS(axis2.NewIOFS(afs), "configs/game.json", "configs/keys.json", "configs/new.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	
+	ro := new(axis2.FileSystem)
+	ro.Mount("", axisfs.NewDir(defaults), true)
+	if err := ro.WriteAll("configs/game.json", nil); err == nil {
+		t.Error("write to read-only fs.FS succeeded")
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package axis2
+
+import "strings"
+
+// Mkdir creates an empty directory at the given path. Like all other changes this is carried out on the write half of
+// the FileSystem.
+// 
+// The parent directory must already exist (though it may only exist on the read half, in which case it is created on
+// the write half as well). If anything already exists at the path an error of type ErrExists is returned.
+// 
+// Directories can only be created in DataSources that implement DirMaker, others return ErrUnsupported. Note
+// that you do not need to create a directory before writing files to it, Write creates any missing directories
+// for you.
+func (fs *FileSystem) Mkdir(path string) error {
+	dirs := validatePath(path)
+	if dirs == nil || len(dirs) == 0 {
+		return &Error{Path: path, Typ: ErrBadPath}
+	}
+	
+	if fs.Exists(path) {
+		return &Error{Path: path, Typ: ErrExists}
+	}
+	parent := strings.Join(dirs[:len(dirs)-1], "/")
+	if !fs.IsDir(parent) {
+		return &Error{Path: parent, Typ: ErrNotFound}
+	}
+	return fs.mkdirs(dirs)
+}
+
+// MkdirAll creates a directory at the given path, along with any missing parents. If the directory already exists
+// on the write half this does nothing.
+// 
+// See Mkdir for more details.
+func (fs *FileSystem) MkdirAll(path string) error {
+	dirs := validatePath(path)
+	if dirs == nil {
+		return &Error{Path: path, Typ: ErrBadPath}
+	}
+	return fs.mkdirs(dirs)
+}
+
+// mkdirs makes sure every directory leading up to and including the given path exists on the write half.
+func (fs *FileSystem) mkdirs(dirs []string) error {
+	for i := 1; i <= len(dirs); i++ {
+		path := strings.Join(dirs[:i], "/")
+		
+		if matches := fs.lookup(dirs[:i], CreateNone, false); len(matches) != 0 {
+			if _, ok := matches[0].ds.(Dir); !ok {
+				return &Error{Path: path, Typ: ErrNotDir}
+			}
+			continue
+		}
+		
+		var parent Dir
+		for _, m := range fs.lookup(dirs[:i-1], CreateNone, false) {
+			if d, ok := m.ds.(Dir); ok {
+				parent = d
+				break
+			}
+		}
+		if parent == nil {
+			// Mount point subsets act like directories, but nothing can be created in them.
+			if fs.isMP(path, false) {
+				continue
+			}
+			if fs.isMP(strings.Join(dirs[:i-1], "/"), false) {
+				return &Error{Path: path, Typ: ErrBadAction}
+			}
+			return &Error{Path: path, Typ: ErrNotFound}
+		}
+		
+		maker, ok := parent.(DirMaker)
+		if !ok {
+			return &Error{Path: path, Typ: ErrUnsupported}
+		}
+		if err := maker.Mkdir(dirs[i-1]); err != nil {
+			return wrapError(err, path)
+		}
+	}
+	return nil
+}
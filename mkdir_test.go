@@ -0,0 +1,78 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package axis2_test
+
+import (
+	"errors"
+	"testing"
+	
+	"github.com/milochristiansen/axis2"
+	"github.com/milochristiansen/axis2/sources/mem"
+)
+
+func TestMkdir(t *testing.T) {
+	afs := new(axis2.FileSystem)
+	afs.Mount("", mem.NewDir(), true)
+	
+	if err := afs.Mkdir("a"); err != nil {
+		t.Fatal(err)
+	}
+	if !afs.IsDir("a") || len(afs.List("a")) != 0 {
+		t.Error("Mkdir did not create an empty directory")
+	}
+	if err := afs.Mkdir("a"); !errors.Is(err, axis2.ErrExistsSentinel) {
+		t.Errorf("Mkdir of existing directory: %v", err)
+	}
+	if err := afs.Mkdir("x/y"); !errors.Is(err, axis2.ErrNotFoundSentinel) {
+		t.Errorf("Mkdir with missing parent: %v", err)
+	}
+	
+	if err := afs.MkdirAll("a/b/c/d"); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"a/b", "a/b/c", "a/b/c/d"} {
+		if !afs.IsDir(path) {
+			t.Errorf("MkdirAll did not create %v", path)
+		}
+	}
+	if err := afs.MkdirAll("a/b"); err != nil {
+		t.Errorf("MkdirAll of existing directory: %v", err)
+	}
+	
+	if err := afs.WriteAll("f.txt", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := afs.MkdirAll("f.txt/b"); !errors.Is(err, axis2.ErrNotDirSentinel) {
+		t.Errorf("MkdirAll through a File: %v", err)
+	}
+	
+	// Without DirMaker directories cannot be created.
+	plain := new(axis2.FileSystem)
+	plain.Mount("", plainDir{mem.NewDir()}, true)
+	if err := plain.Mkdir("a"); !errors.Is(err, axis2.ErrUnsupportedSentinel) {
+		t.Errorf("Mkdir without DirMaker: %v", err)
+	}
+	if err := plain.MkdirAll("a/b"); !errors.Is(err, axis2.ErrUnsupportedSentinel) {
+		t.Errorf("MkdirAll without DirMaker: %v", err)
+	}
+}
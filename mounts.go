@@ -0,0 +1,230 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package axis2
+
+import "fmt"
+import "io"
+import "sort"
+import "strings"
+
+// MountInfo describes a single mounted DataSource, as returned by FileSystem.Mounts.
+type MountInfo struct {
+	// The mount point and the mounted DataSource.
+	MountPoint string
+	Source     DataSource
+	
+	// The Go type of the DataSource (for example "*zip.zdir").
+	Type string
+	
+	// The description returned by the DataSource if it implements Describer, otherwise empty.
+	Description string
+	
+	// The label and priority the DataSource was mounted with (see MountOptions).
+	Label    string
+	Priority int
+	
+	// The position of the DataSource in the read and write halves, or -1 if it is not mounted on that half. When
+	// several DataSources contain the same path the one with the lowest position is used.
+	ReadOrder  int
+	WriteOrder int
+}
+
+// Mounts returns information about every mounted DataSource. DataSources mounted for reading come first, in the order
+// they are searched, followed by any that are only mounted for writing.
+func (fs *FileSystem) Mounts() []MountInfo {
+	t := fs.table.Load()
+	if t == nil {
+		return nil
+	}
+	
+	var rtn []MountInfo
+	for i, src := range t.r {
+		rtn = append(rtn, newMountInfo(src, i, indexOf(src, t.w)))
+	}
+	for i, src := range t.w {
+		if indexOf(src, t.r) == -1 {
+			rtn = append(rtn, newMountInfo(src, -1, i))
+		}
+	}
+	return rtn
+}
+
+func newMountInfo(src *source, r, w int) MountInfo {
+	info := MountInfo{
+		MountPoint: strings.Join(src.mp, "/"),
+		Source: src.ds,
+		Type: fmt.Sprintf("%T", src.ds),
+		Label: src.label,
+		Priority: src.priority,
+		ReadOrder: r,
+		WriteOrder: w,
+	}
+	if d, ok := src.ds.(Describer); ok {
+		info.Description = d.Describe()
+	}
+	return info
+}
+
+func indexOf(src *source, sources []*source) int {
+	for i := range sources {
+		if sources[i] == src {
+			return i
+		}
+	}
+	return -1
+}
+
+// Dump writes a human readable tree of mount points to w, listing the DataSources mounted on each. For example:
+// 
+//	/
+//		sources.osDir (read #2, write #0): /home/user/.game
+//		mods/
+//			base/
+//				*zip.zdir (label "patch", priority 1, read #0): /opt/game/patch.zip
+//				*zip.zdir (read #1): /opt/game/base.zip
+func (fs *FileSystem) Dump(w io.Writer) error {
+	mounts := fs.Mounts()
+	
+	// Every mount point, and every directory leading to one, gets a line of its own.
+	nodes := map[string][]string{"": nil}
+	for _, m := range mounts {
+		dirs := validatePath(m.MountPoint)
+		for i := 1; i <= len(dirs); i++ {
+			nodes[strings.Join(dirs[:i], "/")] = dirs[:i]
+		}
+	}
+	paths := make([]string, 0, len(nodes))
+	for path := range nodes {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		return lessParts(nodes[paths[i]], nodes[paths[j]])
+	})
+	
+	for _, path := range paths {
+		dirs := nodes[path]
+		indent := strings.Repeat("\t", len(dirs))
+		
+		name := "/"
+		if len(dirs) != 0 {
+			name = dirs[len(dirs)-1] + "/"
+		}
+		if _, err := fmt.Fprintf(w, "%v%v\n", indent, name); err != nil {
+			return err
+		}
+		
+		for _, m := range mounts {
+			if m.MountPoint != path {
+				continue
+			}
+			
+			var notes []string
+			if m.Label != "" {
+				notes = append(notes, fmt.Sprintf("label %q", m.Label))
+			}
+			if m.Priority != 0 {
+				notes = append(notes, fmt.Sprintf("priority %v", m.Priority))
+			}
+			if m.ReadOrder != -1 {
+				notes = append(notes, fmt.Sprintf("read #%v", m.ReadOrder))
+			}
+			if m.WriteOrder != -1 {
+				notes = append(notes, fmt.Sprintf("write #%v", m.WriteOrder))
+			}
+			line := fmt.Sprintf("%v\t%v (%v)", indent, m.Type, strings.Join(notes, ", "))
+			if m.Description != "" {
+				line += ": " + m.Description
+			}
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// lessParts sorts paths so that each directory comes directly before its contents.
+func lessParts(a, b []string) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// Resolution describes where an item in a FileSystem comes from, as returned by FileSystem.Resolve.
+type Resolution struct {
+	// The position of the DataSource in the read half (see MountInfo.ReadOrder).
+	Index int
+	
+	// The mount point and the mounted DataSource the item was found in.
+	MountPoint string
+	Source     DataSource
+	
+	// The item itself.
+	Item DataSource
+	
+	// The description returned by the item if it implements Describer (for example the absolute OS path of a file,
+	// or the name of a zip file followed by the path inside it), otherwise empty.
+	Locator string
+}
+
+// Resolve returns the item that would be used if the path was read, along with where it comes from.
+// This is mostly useful for finding out why an item in one DataSource is used instead of one in another.
+// 
+// Errors are the same as GetDSAt.
+func (fs *FileSystem) Resolve(path string) (*Resolution, error) {
+	rtn, err := fs.ResolveAll(path)
+	if err != nil {
+		return nil, err
+	}
+	return rtn[0], nil
+}
+
+// ResolveAll is like Resolve, but it returns every item that matches the path, in the order they are searched.
+func (fs *FileSystem) ResolveAll(path string) ([]*Resolution, error) {
+	dirs := validatePath(path)
+	if dirs == nil {
+		return nil, &Error{Path: path, Typ: ErrBadPath}
+	}
+	
+	matches, err := fs.matches(path, dirs, CreateNone, true)
+	if err != nil {
+		return nil, err
+	}
+	rtn := make([]*Resolution, 0, len(matches))
+	for _, m := range matches {
+		res := &Resolution{
+			Index: m.n,
+			MountPoint: strings.Join(m.src.mp, "/"),
+			Source: m.src.ds,
+			Item: m.ds,
+		}
+		if d, ok := m.ds.(Describer); ok {
+			res.Locator = d.Describe()
+		}
+		rtn = append(rtn, res)
+	}
+	return rtn, nil
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package axis2_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	
+	"github.com/milochristiansen/axis2"
+	"github.com/milochristiansen/axis2/sources"
+	"github.com/milochristiansen/axis2/sources/mem"
+	"github.com/milochristiansen/axis2/sources/zip"
+)
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	zpath := filepath.Join(dir, "base.zip")
+	if err := os.WriteFile(zpath, data, 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b.txt"), []byte("override"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	
+	zfile, err := os.Open(zpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zfile.Close()
+	info, err := zfile.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	base, err := zip.NewDir(zfile, info.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	
+	afs := new(axis2.FileSystem)
+	afs.Mount("", sources.NewOSDir(dir), true)
+	afs.Mount("", base, false)
+	afs.AutoMount(".zip", zip.OpenFile)
+	
+	all, err := afs.ResolveAll("b.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("unexpected number of results: %v", len(all))
+	}
+	want := []string{filepath.Join(dir, "b.txt"), zpath + ":b.txt"}
+	for i, res := range all {
+		if res.Index != i || res.MountPoint != "" || res.Locator != want[i] {
+			t.Errorf("unexpected result %v: %+v", i, res)
+		}
+	}
+	
+	res, err := afs.Resolve("base.zip/a/x.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Locator != zpath+":a/x.txt" || res.Index != 0 {
+		t.Errorf("unexpected result for auto mounted archive: %+v", res)
+	}
+	
+	if _, err := afs.Resolve("missing.txt"); err == nil {
+		t.Error("missing file resolved")
+	}
+	
+	mounts := afs.Mounts()
+	if len(mounts) != 2 || mounts[0].Description != dir || mounts[0].WriteOrder != 0 || mounts[1].WriteOrder != -1 {
+		t.Errorf("unexpected Mounts result: %+v", mounts)
+	}
+}
+
+func TestBind(t *testing.T) {
+	global := new(axis2.FileSystem)
+	global.Mount("", mem.NewDir(), true)
+	if err := global.WriteAll("shared/textures/a.png", []byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	
+	textures, err := global.Bind("shared/textures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := global.Mount("modA/textures", textures, true); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := global.ReadAll("modA/textures/a.png"); string(content) != "a" {
+		t.Errorf("unexpected contents through bind: %q", content)
+	}
+	
+	// A per-tenant FileSystem built from part of the global one.
+	tenant := new(axis2.FileSystem)
+	if err := tenant.Mount("textures", textures, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := tenant.Mount("", mem.NewDir(), true); err != nil {
+		t.Fatal(err)
+	}
+	if err := tenant.WriteAll("textures/b.png", []byte("b")); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := global.ReadAll("shared/textures/b.png"); string(content) != "b" {
+		t.Errorf("write through bind went to the wrong place: %q", content)
+	}
+	if !tenant.Exists("textures/b.png") || tenant.Exists("shared") {
+		t.Error("tenant sees the wrong items")
+	}
+	file, err := global.Bind("shared/textures/a.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tenant.Mount("a.png", file, false); err != nil {
+		t.Fatal(err)
+	}
+	if tenant.Size("a.png") != 1 {
+		t.Errorf("unexpected size for bound file: %v", tenant.Size("a.png"))
+	}
+	
+	if _, err := global.Bind("missing"); !errors.Is(err, axis2.ErrNotFoundSentinel) {
+		t.Errorf("bind of missing path: %v", err)
+	}
+	
+	// Cycles, including ones that go through more than one FileSystem.
+	for _, path := range []string{"shared/textures/loop", "shared"} {
+		if err := global.Mount(path, textures, false); !errors.Is(err, axis2.ErrBadActionSentinel) {
+			t.Errorf("%v: expected cycle to be detected: %v", path, err)
+		}
+	}
+	modA, err := global.Bind("modA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := global.Mount("shared/textures/modA", modA, false); !errors.Is(err, axis2.ErrBadActionSentinel) {
+		t.Errorf("expected cycle through modA to be detected: %v", err)
+	}
+	back, err := tenant.Bind("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := global.Mount("shared/textures/tenant", back, false); !errors.Is(err, axis2.ErrBadActionSentinel) {
+		t.Errorf("expected cycle through tenant to be detected: %v", err)
+	}
+	if err := global.Mount("tenant", back, false); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !global.Exists("tenant/textures/a.png") {
+		t.Error("nested bind does not work")
+	}
+}
+
+func TestMountWith(t *testing.T) {
+	layer := func(content string) axis2.DataSource {
+		return memDir(t, map[string]string{"a.txt": content})
+	}
+	
+	afs := new(axis2.FileSystem)
+	afs.Mount("", layer("base"), false)
+	read := func() string {
+		content, _ := afs.ReadAll("a.txt")
+		return string(content)
+	}
+	
+	if err := afs.MountWith("", layer("patch"), false, axis2.MountOptions{Label: "patch", Priority: 10}); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(); got != "patch" {
+		t.Errorf("priority ignored, got %q", got)
+	}
+	afs.Mount("", layer("late"), false)
+	if got := read(); got != "patch" {
+		t.Errorf("plain mount took precedence, got %q", got)
+	}
+	
+	if err := afs.MountWith("", layer("hotfix"), false, axis2.MountOptions{Label: "hotfix", Before: "patch"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := afs.MountWith("", layer("after"), false, axis2.MountOptions{After: "patch"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := afs.MountWith("", layer("front"), false, axis2.MountOptions{Front: true}); err != nil {
+		t.Fatal(err)
+	}
+	
+	var order []string
+	for _, m := range afs.Mounts() {
+		order = append(order, m.Label)
+	}
+	if len(order) != 6 || order[0] != "hotfix" || order[1] != "patch" || afs.Mounts()[2].Priority != 10 {
+		t.Errorf("unexpected mount order: %q", order)
+	}
+	for i, want := range []string{"hotfix", "patch", "after", "front", "base", "late"} {
+		ofs := new(axis2.FileSystem)
+		ofs.Mount("", afs.Mounts()[i].Source, false)
+		if content, _ := ofs.ReadAll("a.txt"); string(content) != want {
+			t.Errorf("mount #%v: got %q, want %q", i, content, want)
+		}
+	}
+	if got := read(); got != "hotfix" {
+		t.Errorf("got %q, want hotfix", got)
+	}
+	
+	if err := afs.MountWith("", layer("x"), false, axis2.MountOptions{Label: "patch"}); !errors.Is(err, axis2.ErrExistsSentinel) {
+		t.Errorf("duplicate label: %v", err)
+	}
+	if err := afs.MountWith("", layer("x"), false, axis2.MountOptions{Before: "missing"}); !errors.Is(err, axis2.ErrNotFoundSentinel) {
+		t.Errorf("missing label: %v", err)
+	}
+	
+	old := afs.SwapLabel("hotfix", layer("hotfix2"))
+	if old == nil || read() != "hotfix2" || afs.Mounts()[0].Label != "hotfix" {
+		t.Errorf("SwapLabel failed, got %q", read())
+	}
+	if !afs.UnmountLabel("hotfix") || afs.UnmountLabel("hotfix") {
+		t.Error("UnmountLabel returned the wrong result")
+	}
+	if got := read(); got != "patch" {
+		t.Errorf("got %q, want patch", got)
+	}
+	if len(afs.Mounts()) != 5 {
+		t.Errorf("UnmountLabel removed the wrong mounts: %+v", afs.Mounts())
+	}
+}
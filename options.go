@@ -0,0 +1,213 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package axis2
+
+import "fmt"
+
+// MountOptions controls where a DataSource is placed in the mount table by MountWith.
+// 
+// The zero value places the DataSource after everything else, exactly like Mount.
+type MountOptions struct {
+	// A name for the mount, so that it can be found with UnmountLabel, SwapLabel, Before, and After. Labels must be
+	// unique within a FileSystem, unlabelled mounts are allowed to share the empty label.
+	Label string
+	
+	// DataSources with a higher priority are tried before those with a lower priority. DataSources mounted with Mount
+	// have a priority of 0.
+	Priority int
+	
+	// If true the DataSource is placed before any others with the same priority, rather than after them.
+	Front bool
+	
+	// Place the DataSource directly before or after the DataSource with the given label, ignoring Priority and Front
+	// (the new DataSource gets the same priority as the labelled one). Only one of these may be set.
+	// 
+	// The write half is only affected if the labelled DataSource is mounted on it, otherwise the new DataSource is
+	// placed on the write half by priority.
+	Before string
+	After  string
+}
+
+// MountWith is like Mount, except it uses the given options to decide where the DataSource is placed, allowing it to
+// take precedence over DataSources that are already mounted.
+// 
+// If the label is already in use an error of type ErrExists is returned, if there is no DataSource with the label
+// given by Before or After an error of type ErrNotFound is returned, and if both Before and After are set an error
+// of type ErrBadAction is returned.
+func (fs *FileSystem) MountWith(path string, ds DataSource, rw bool, opts MountOptions) error {
+	dirs := validatePath(path)
+	if dirs == nil {
+		return &Error{Path: path, Typ: ErrBadPath}
+	}
+	
+	// Ensure the mounted item implements either File or Dir (or both).
+	_, a := ds.(File); _, b := ds.(Dir)
+	if !a && !b {
+		return &Error{Path: path, Typ: ErrBadAction}
+	}
+	if opts.Before != "" && opts.After != "" {
+		return &Error{Path: path, Typ: ErrBadAction}
+	}
+	
+	src := &source{
+		mp: dirs,
+		ds: ds,
+		label: opts.Label,
+		priority: opts.Priority,
+	}
+	var err error
+	fs.update(func(t *mountTable) {
+		if opts.Label != "" && (findLabel(opts.Label, t.r) != -1 || findLabel(opts.Label, t.w) != -1) {
+			err = &Error{Path: path, Typ: ErrExists, Err: fmt.Errorf("label %q is already in use", opts.Label)}
+			return
+		}
+		
+		rel, after := opts.Before, false
+		if opts.After != "" {
+			rel, after = opts.After, true
+		}
+		if rel != "" {
+			i := findLabel(rel, t.r)
+			if i == -1 {
+				err = &Error{Path: path, Typ: ErrNotFound, Err: fmt.Errorf("no mount with label %q", rel)}
+				return
+			}
+			src.priority = t.r[i].priority
+		}
+		
+		if fs.bindCycle(t, dirs, ds) {
+			err = &Error{Path: path, Typ: ErrBadAction}
+			return
+		}
+		
+		t.r = insertSource(t.r, src, rel, after, opts.Front)
+		if rw {
+			t.w = insertSource(t.w, src, rel, after, opts.Front)
+		}
+	})
+	return err
+}
+
+// insertSource adds src to sources, directly before (or after) the source with the label rel if there is one, otherwise
+// after (or before, if front is true) the other sources with the same priority.
+func insertSource(sources []*source, src *source, rel string, after, front bool) []*source {
+	i := -1
+	if rel != "" {
+		i = findLabel(rel, sources)
+		if i != -1 && after {
+			i++
+		}
+	}
+	if i == -1 {
+		i = len(sources)
+		for k := range sources {
+			if sources[k].priority < src.priority || front && sources[k].priority == src.priority {
+				i = k
+				break
+			}
+		}
+	}
+	
+	sources = append(sources, nil)
+	copy(sources[i+1:], sources[i:])
+	sources[i] = src
+	return sources
+}
+
+// UnmountLabel removes the DataSource with the given label from both halves. Returns false if there is no DataSource
+// with that label.
+func (fs *FileSystem) UnmountLabel(label string) bool {
+	if label == "" {
+		return false
+	}
+	
+	found := false
+	fs.update(func(t *mountTable) {
+		if i := findLabel(label, t.r); i != -1 {
+			t.r = append(t.r[:i], t.r[i+1:]...)
+			found = true
+		}
+		if i := findLabel(label, t.w); i != -1 {
+			t.w = append(t.w[:i], t.w[i+1:]...)
+			found = true
+		}
+	})
+	return found
+}
+
+// SwapLabel replaces the DataSource with the given label and returns the old one. The new DataSource keeps the mount
+// point, label, priority, and position of the old one on both halves.
+// Returns nil on error.
+func (fs *FileSystem) SwapLabel(label string, ds DataSource) DataSource {
+	if label == "" {
+		return nil
+	}
+	
+	_, a := ds.(File); _, b := ds.(Dir)
+	if !a && !b {
+		return nil
+	}
+	
+	var rtn DataSource
+	fs.update(func(t *mountTable) {
+		i, k := findLabel(label, t.r), findLabel(label, t.w)
+		var old *source
+		switch {
+		case i != -1:
+			old = t.r[i]
+		case k != -1:
+			old = t.w[k]
+		default:
+			return
+		}
+		if fs.bindCycle(t, old.mp, ds) {
+			return
+		}
+		
+		// Sources are shared by the old tables, so they must be replaced rather than changed.
+		src := &source{
+			mp: old.mp,
+			ds: ds,
+			label: old.label,
+			priority: old.priority,
+		}
+		rtn = old.ds
+		if i != -1 {
+			t.r[i] = src
+		}
+		if k != -1 {
+			t.w[k] = src
+		}
+	})
+	return rtn
+}
+
+// findLabel returns the index of the source with the given label, or -1 if there isn't one.
+func findLabel(label string, sources []*source) int {
+	for i := range sources {
+		if sources[i].label == label {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package axis2_test
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+	
+	"github.com/milochristiansen/axis2"
+	axisfs "github.com/milochristiansen/axis2/sources/iofs"
+	"github.com/milochristiansen/axis2/sources/mem"
+)
+
+func TestWhiteouts(t *testing.T) {
+	defaults := fstest.MapFS{
+		"configs/game.json": {Data: []byte("default")},
+		"configs/keys.json": {Data: []byte("keys")},
+		"data/a.txt":        {Data: []byte("a")},
+	}
+	list := func(afs *axis2.FileSystem, path string) string {
+		names := afs.List(path)
+		sort.Strings(names)
+		return strings.Join(names, " ")
+	}
+	
+	afs := new(axis2.FileSystem)
+	afs.Mount("", mem.NewDir(), true)
+	afs.Mount("", axisfs.NewDir(defaults), false)
+	
+	// Without whiteouts nothing on the read half can be deleted.
+	if err := afs.Delete("configs/keys.json"); !errors.Is(err, axis2.ErrNotFoundSentinel) {
+		t.Errorf("unexpected error with whiteouts off: %v", err)
+	}
+	
+	afs.Whiteouts(true)
+	if err := afs.Delete("configs/keys.json"); err != nil {
+		t.Fatal(err)
+	}
+	if afs.Exists("configs/keys.json") {
+		t.Error("configs/keys.json still exists")
+	}
+	if got := list(afs, "configs"); got != "game.json" {
+		t.Errorf("unexpected List result: %q", got)
+	}
+	if got := afs.ListFiles("configs"); len(got) != 1 {
+		t.Errorf("unexpected ListFiles result: %v", got)
+	}
+	if afs.Exists("configs/" + axis2.WhiteoutPrefix + "keys.json") {
+		t.Error("whiteout marker is visible")
+	}
+	
+	// Items written over a whiteout are visible, and can be deleted again.
+	if err := afs.WriteAll("configs/keys.json", []byte("user")); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := afs.ReadAll("configs/keys.json"); string(content) != "user" {
+		t.Errorf("unexpected contents: %q", content)
+	}
+	if err := afs.Delete("configs/keys.json"); err != nil {
+		t.Fatal(err)
+	}
+	if afs.Exists("configs/keys.json") {
+		t.Error("configs/keys.json came back after being deleted twice")
+	}
+	
+	// Whole directories.
+	if err := afs.RemoveAll("configs"); err != nil {
+		t.Fatal(err)
+	}
+	if afs.Exists("configs/game.json") || list(afs, "") != "data" {
+		t.Errorf("configs was not removed: %q", list(afs, ""))
+	}
+	if err := afs.Mkdir("configs"); err != nil {
+		t.Fatal(err)
+	}
+	if got := list(afs, "configs"); got != "" {
+		t.Errorf("recreated directory is not empty: %q", got)
+	}
+	
+	// Mount points are emptied with an opaque marker instead.
+	mp := new(axis2.FileSystem)
+	mp.Whiteouts(true)
+	mp.Mount("mods", mem.NewDir(), true)
+	mp.Mount("mods", axisfs.NewDir(defaults), false)
+	if err := mp.RemoveAll("mods"); err != nil {
+		t.Fatal(err)
+	}
+	if !mp.Exists("mods") || list(mp, "mods") != "" || mp.Exists("mods/data/a.txt") {
+		t.Errorf("mods was not emptied: %q", list(mp, "mods"))
+	}
+	
+	// A marker can only hide things from DataSources that come after it.
+	back := new(axis2.FileSystem)
+	back.Whiteouts(true)
+	back.Mount("", axisfs.NewDir(defaults), false)
+	back.Mount("", mem.NewDir(), true)
+	if err := back.Delete("configs/keys.json"); !errors.Is(err, axis2.ErrReadOnlySentinel) {
+		t.Errorf("unexpected error for a read-only item: %v", err)
+	}
+}
+
+func TestWhiteoutMounts(t *testing.T) {
+	mod := fstest.MapFS{"a.txt": {Data: []byte("a")}}
+	
+	// Write the markers without whiteouts turned on, since they are not visible otherwise.
+	top := mem.NewDir()
+	setup := new(axis2.FileSystem)
+	setup.Mount("", top, true)
+	for _, name := range []string{"x", "y", "w"} {
+		if err := setup.WriteAll("mods/"+axis2.WhiteoutPrefix+name, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	
+	afs := new(axis2.FileSystem)
+	afs.Whiteouts(true)
+	afs.Mount("mods/w", axisfs.NewDir(mod), false) // Before the marker, so still visible.
+	afs.Mount("", top, false)
+	afs.Mount("mods/x", axisfs.NewDir(mod), false)
+	afs.Mount("mods/y/deep", axisfs.NewDir(mod), false)
+	afs.Mount("mods/z", axisfs.NewDir(mod), false)
+	
+	for _, f := range []func(string) []string{afs.List, afs.ListDirs} {
+		names := f("mods")
+		sort.Strings(names)
+		if got := strings.Join(names, " "); got != "w z" {
+			t.Errorf("unexpected mount points listed: %q", got)
+		}
+	}
+	if names := afs.List("mods/y"); len(names) != 0 {
+		t.Errorf("hidden mount point subset listed: %v", names)
+	}
+	if afs.Exists("mods/x/a.txt") || !afs.Exists("mods/w/a.txt") {
+		t.Error("unexpected lookup results")
+	}
+	
+	// Without whiteouts everything is visible (along with the markers).
+	afs.Whiteouts(false)
+	if names := afs.ListDirs("mods"); len(names) != 4 {
+		t.Errorf("unexpected mount points listed without whiteouts: %v", names)
+	}
+}
+
+func TestAppendCopyUp(t *testing.T) {
+	defaults := fstest.MapFS{
+		"log.txt": {Data: []byte("default"), Mode: 0640},
+	}
+	
+	user := mem.NewDir()
+	afs := new(axis2.FileSystem)
+	afs.Mount("", user, true)
+	afs.Mount("", axisfs.NewDir(defaults), false)
+	
+	for _, s := range []string{" one", " two"} {
+		w, err := afs.Append("log.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(s))
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	
+	if content, _ := afs.ReadAll("log.txt"); string(content) != "default one two" {
+		t.Errorf("unexpected contents: %q", content)
+	}
+	if string(defaults["log.txt"].Data) != "default" {
+		t.Error("read-only layer was changed")
+	}
+	
+	info, err := user.Child("log.txt", axis2.CreateNone).(axis2.Stater).Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0640 {
+		t.Errorf("metadata was not copied: %v", info.Mode())
+	}
+}
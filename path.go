@@ -62,3 +62,16 @@ func validatePath(path string) []string {
 	
 	return dirs
 }
+
+// hasPrefix returns true if the first elements of dirs are the same as prefix.
+func hasPrefix(dirs, prefix []string) bool {
+	if len(prefix) > len(dirs) {
+		return false
+	}
+	for i := range prefix {
+		if dirs[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
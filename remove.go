@@ -0,0 +1,128 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package axis2
+
+import "strings"
+
+// RemoveAll deletes the item at the given path and everything it contains. Like all other changes this is carried out
+// on the write half of the FileSystem.
+// 
+// Unlike Delete, which only deletes the first item it finds, RemoveAll removes the item from every DataSource mounted
+// for writing that contains it. Directories are emptied bottom-up. DataSources mounted directly on the path, or
+// anywhere inside it, are emptied, but they stay mounted.
+// 
+// If whiteouts are turned on (see Whiteouts) and the item can still be found on the read half afterwards, it is hidden
+// with a whiteout marker. If the path is a mount point an opaque marker is used instead, so it appears empty.
+// 
+// RemoveAll removes everything it can, then returns a MultiError listing everything that could not be removed (for
+// example read-only items in a zip file), or nil if there were no problems. If nothing exists at the path this
+// returns nil.
+func (fs *FileSystem) RemoveAll(path string) error {
+	dirs := validatePath(path)
+	if dirs == nil {
+		return &Error{Path: path, Typ: ErrBadPath}
+	}
+	
+	var errs MultiError
+	var parents []match
+	if len(dirs) > 0 {
+		parents = fs.lookup(dirs[:len(dirs)-1], CreateNone, false)
+	}
+	for _, m := range fs.lookup(dirs, CreateNone, false) {
+		if len(m.src.mp) == len(dirs) {
+			// Can't delete a mounted DataSource, so just empty it.
+			if d, ok := m.ds.(Dir); ok {
+				for _, name := range d.List() {
+					removeAll(d, name, joinPath(path, name), &errs)
+				}
+			}
+			continue
+		}
+		
+		for _, p := range parents {
+			if p.src == m.src {
+				removeAll(p.ds.(Dir), dirs[len(dirs)-1], path, &errs)
+				break
+			}
+		}
+	}
+	
+	// DataSources mounted inside the path are not found by the lookup above.
+	for _, src := range fs.sources(false) {
+		if len(src.mp) <= len(dirs) || !hasPrefix(src.mp, dirs) {
+			continue
+		}
+		if d, ok := src.ds.(Dir); ok {
+			mp := strings.Join(src.mp, "/")
+			for _, name := range d.List() {
+				removeAll(d, name, joinPath(mp, name), &errs)
+			}
+		}
+	}
+	
+	if t := fs.table.Load(); t != nil && t.whiteouts {
+		if _, err := fs.whiteout(dirs, true); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// removeAll recursively deletes the child id of dir, children first. Errors are added to errs rather than returned,
+// and removeAll keeps going after a failure. Returns true if the item was removed.
+func removeAll(dir Dir, id, path string, errs *MultiError) bool {
+	ok := true
+	if d, isDir := dir.Child(id, CreateNone).(Dir); isDir {
+		for _, name := range d.List() {
+			if !removeAll(d, name, joinPath(path, name), errs) {
+				ok = false
+			}
+		}
+	}
+	if !ok {
+		// There is no point trying to delete a directory that could not be emptied.
+		return false
+	}
+	
+	if err := dir.Delete(id); err != nil {
+		*errs = append(*errs, wrapError(err, path))
+		return false
+	}
+	return true
+}
+
+// removeDS recursively deletes the child id of dir, children first.
+func removeDS(dir Dir, id string) error {
+	if d, ok := dir.Child(id, CreateNone).(Dir); ok {
+		for _, name := range d.List() {
+			if err := removeDS(d, name); err != nil {
+				return err
+			}
+		}
+	}
+	return dir.Delete(id)
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package axis2_test
+
+import (
+	"errors"
+	"testing"
+	
+	"github.com/milochristiansen/axis2"
+	"github.com/milochristiansen/axis2/sources/mem"
+	"github.com/milochristiansen/axis2/sources/zip"
+)
+
+func TestRemoveAll(t *testing.T) {
+	afs := new(axis2.FileSystem)
+	x, y := mem.NewDir(), mem.NewDir()
+	afs.Mount("mods/x", x, true)
+	afs.Mount("mods/deep/y", y, true)
+	afs.Mount("", mem.NewDir(), true)
+	
+	for _, name := range []string{"mods/a.txt", "mods/b/c.txt", "mods/x/d/e.txt", "mods/deep/y/f.txt", "keep.txt"} {
+		if err := afs.WriteAll(name, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	
+	if err := afs.RemoveAll("mods"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"mods/a.txt", "mods/b", "mods/x/d", "mods/deep/y/f.txt"} {
+		if afs.Exists(name) {
+			t.Errorf("%v was not removed", name)
+		}
+	}
+	if len(x.List()) != 0 || len(y.List()) != 0 {
+		t.Error("DataSources mounted inside the path were not emptied")
+	}
+	if !afs.Exists("keep.txt") || !afs.IsMP("mods") {
+		t.Error("RemoveAll removed too much")
+	}
+	if err := afs.RemoveAll("missing"); err != nil {
+		t.Errorf("RemoveAll of missing path: %v", err)
+	}
+	
+	// Anything that can't be removed is reported, but everything else is still removed.
+	z, err := zip.NewRawDir(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	afs.Mount("mods/z", z, true)
+	if err := afs.WriteAll("mods/x/g.txt", nil); err != nil {
+		t.Fatal(err)
+	}
+	err = afs.RemoveAll("mods")
+	var errs axis2.MultiError
+	if !errors.As(err, &errs) || !errors.Is(errs[0], axis2.ErrReadOnlySentinel) {
+		t.Errorf("expected a MultiError of ErrReadOnly, got: %v", err)
+	}
+	if afs.Exists("mods/x/g.txt") {
+		t.Error("mods/x/g.txt was not removed")
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package axis2
+
+import "strings"
+
+// Rename moves the item at oldpath to newpath. Like all other changes this is carried out on the write half of the
+// FileSystem.
+// 
+// If both paths are in the same mounted DataSource and its Dirs implement Renamer the item is renamed natively,
+// otherwise the item is copied to the new location (recursively if it is a Dir) and then deleted from the old one.
+// 
+// Rename never replaces anything, if an item already exists at newpath in the write half an error of type ErrExists
+// is returned. Renaming something that only exists in the read half returns ErrReadOnly, and renaming a mounted
+// DataSource itself returns ErrUnsupported (use SwapMount or Unmount and Mount for that).
+// 
+// If the item is copied and deleting the original fails, the copy is kept, so the item may be left (partly) in both
+// places, but nothing is lost.
+func (fs *FileSystem) Rename(oldpath, newpath string) error {
+	odirs := validatePath(oldpath)
+	if odirs == nil || len(odirs) == 0 {
+		return &Error{Path: oldpath, Typ: ErrBadPath}
+	}
+	ndirs := validatePath(newpath)
+	if ndirs == nil || len(ndirs) == 0 {
+		return &Error{Path: newpath, Typ: ErrBadPath}
+	}
+	if strings.Join(odirs, "/") == strings.Join(ndirs, "/") {
+		return nil
+	}
+	if len(ndirs) > len(odirs) && strings.Join(ndirs[:len(odirs)], "/") == strings.Join(odirs, "/") {
+		// Can't move a directory inside itself.
+		return &Error{Path: newpath, Typ: ErrBadAction}
+	}
+	
+	// Find the item and the Dir that holds it.
+	oname := odirs[len(odirs)-1]
+	var from match
+	var ods DataSource
+	for _, m := range fs.lookup(odirs[:len(odirs)-1], CreateNone, false) {
+		if d, ok := m.ds.(Dir); ok {
+			if ods = d.Child(oname, CreateNone); ods != nil {
+				from = m
+				break
+			}
+		}
+	}
+	if ods == nil {
+		if len(fs.lookup(odirs, CreateNone, false)) != 0 {
+			return &Error{Path: oldpath, Typ: ErrUnsupported}
+		}
+		if fs.Exists(oldpath) {
+			return &Error{Path: oldpath, Typ: ErrReadOnly}
+		}
+		return &Error{Path: oldpath, Typ: ErrNotFound}
+	}
+	
+	if len(fs.lookup(ndirs, CreateNone, false)) != 0 {
+		return &Error{Path: newpath, Typ: ErrExists}
+	}
+	
+	// Find where the item is going, preferring the DataSource it is already in.
+	nname := ndirs[len(ndirs)-1]
+	targets := fs.lookup(ndirs[:len(ndirs)-1], CreateDir, false)
+	var to Dir
+	for _, m := range targets {
+		d, ok := m.ds.(Dir)
+		if !ok {
+			continue
+		}
+		if to == nil {
+			to = d
+		}
+		if m.src != from.src {
+			continue
+		}
+		
+		if r, ok := from.ds.(Renamer); ok {
+			err := r.Rename(oname, d, nname)
+			if e, ok := err.(*Error); !ok || e.Typ != ErrUnsupported {
+				return wrapError(err, oldpath)
+			}
+		}
+		to = d
+		break
+	}
+	if to == nil {
+		if fs.isMP(strings.Join(ndirs[:len(ndirs)-1], "/"), false) {
+			return &Error{Path: newpath, Typ: ErrUnsupported}
+		}
+		return &Error{Path: newpath, Typ: ErrNotFound}
+	}
+	
+	// No native rename, so copy then delete.
+	if err := copyDS(ods, to, nname); err != nil {
+		// Don't leave a partial copy behind.
+		if to.Child(nname, CreateNone) != nil {
+			removeDS(to, nname)
+		}
+		return wrapError(err, newpath)
+	}
+	if err := removeDS(from.ds.(Dir), oname); err != nil {
+		// Some of the original may already be gone, so the copy has to stay.
+		return wrapError(err, oldpath)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package axis2_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+	
+	"github.com/milochristiansen/axis2"
+	"github.com/milochristiansen/axis2/sources/mem"
+)
+
+// memDir returns a new mem.Dir containing the given files, keyed by path.
+func memDir(t *testing.T, files map[string]string) *mem.Dir {
+	t.Helper()
+	
+	ds := mem.NewDir()
+	afs := new(axis2.FileSystem)
+	afs.Mount("", ds, true)
+	for path, content := range files {
+		if err := afs.WriteAll(path, []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return ds
+}
+
+// countingDir counts native renames.
+type countingDir struct {
+	*mem.Dir
+	renames *int
+}
+
+func (d countingDir) Rename(id string, to axis2.Dir, toid string) error {
+	*d.renames++
+	if c, ok := to.(countingDir); ok {
+		to = c.Dir
+	}
+	return d.Dir.Rename(id, to, toid)
+}
+
+// plainDir hides everything except the Dir interface (so there is no native rename).
+type plainDir struct {
+	axis2.Dir
+}
+
+// brokenDir is a Dir where any File named "bad.txt" cannot be read.
+type brokenDir struct {
+	axis2.Dir
+}
+
+func (d brokenDir) Child(id string, create int) axis2.DataSource {
+	switch c := d.Dir.Child(id, create).(type) {
+	case axis2.Dir:
+		return brokenDir{c}
+	case axis2.File:
+		if id == "bad.txt" {
+			return brokenFile{c}
+		}
+		return c
+	}
+	return nil
+}
+
+type brokenFile struct {
+	axis2.File
+}
+
+func (brokenFile) Read() (io.ReadCloser, error) {
+	return nil, errors.New("broken")
+}
+
+// lockedDir is a Dir where any item named "locked.txt" cannot be deleted.
+type lockedDir struct {
+	axis2.Dir
+}
+
+func (d lockedDir) Child(id string, create int) axis2.DataSource {
+	if c, ok := d.Dir.Child(id, create).(axis2.Dir); ok {
+		return lockedDir{c}
+	}
+	return d.Dir.Child(id, create)
+}
+
+func (d lockedDir) Delete(id string) error {
+	if id == "locked.txt" {
+		return axis2.NewError(axis2.ErrPermission)
+	}
+	return d.Dir.Delete(id)
+}
+
+func TestRename(t *testing.T) {
+	renames := 0
+	native := new(axis2.FileSystem)
+	native.Mount("", countingDir{mem.NewDir(), &renames}, true)
+	
+	copied := new(axis2.FileSystem)
+	copied.Mount("", plainDir{mem.NewDir()}, true)
+	
+	for name, afs := range map[string]*axis2.FileSystem{"native": native, "copy": copied} {
+		if err := afs.WriteAll("a.txt", []byte("a")); err != nil {
+			t.Fatal(err)
+		}
+		if err := afs.Rename("a.txt", "b.txt"); err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		if content, _ := afs.ReadAll("b.txt"); afs.Exists("a.txt") || string(content) != "a" {
+			t.Errorf("%v: rename failed, b.txt contains %q", name, content)
+		}
+	}
+	if renames != 1 {
+		t.Errorf("unexpected number of native renames: %v", renames)
+	}
+	
+	// Between DataSources.
+	afs := new(axis2.FileSystem)
+	afs.Mount("x", mem.NewDir(), true)
+	afs.Mount("y", mem.NewDir(), true)
+	if err := afs.WriteAll("x/d/a.txt", []byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := afs.Rename("x/d", "y/d"); err != nil {
+		t.Fatal(err)
+	}
+	if afs.Exists("x/d") || !afs.Exists("y/d/a.txt") {
+		t.Error("rename between DataSources failed")
+	}
+	
+	if err := afs.WriteAll("x/a.txt", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := afs.Rename("x/a.txt", "y/d/a.txt"); !errors.Is(err, axis2.ErrExistsSentinel) {
+		t.Errorf("rename over existing item: %v", err)
+	}
+	if err := afs.Rename("x", "z"); !errors.Is(err, axis2.ErrUnsupportedSentinel) {
+		t.Errorf("rename of mount point: %v", err)
+	}
+	if err := afs.Rename("y/d", "y/d/e"); !errors.Is(err, axis2.ErrBadActionSentinel) {
+		t.Errorf("rename into itself: %v", err)
+	}
+	
+	afs.Mount("", memDir(t, map[string]string{"r.txt": ""}), false)
+	if err := afs.Rename("r.txt", "x/r.txt"); !errors.Is(err, axis2.ErrReadOnlySentinel) {
+		t.Errorf("rename of read-only item: %v", err)
+	}
+}
+
+func TestRenamePartialCopy(t *testing.T) {
+	afs := new(axis2.FileSystem)
+	src := memDir(t, map[string]string{"d/a.txt": "", "d/bad.txt": "", "d/e/z.txt": ""})
+	afs.Mount("src", brokenDir{src}, true)
+	afs.Mount("dst", mem.NewDir(), true)
+	
+	if err := afs.Rename("src/d", "dst/d"); err == nil {
+		t.Fatal("rename succeeded even though a File could not be read")
+	}
+	if afs.Exists("dst/d") {
+		t.Error("partial copy left behind")
+	}
+	if !afs.Exists("src/d/a.txt") || !afs.Exists("src/d/bad.txt") {
+		t.Error("source damaged by failed rename")
+	}
+}
+
+func TestRenamePartialDelete(t *testing.T) {
+	afs := new(axis2.FileSystem)
+	src := memDir(t, map[string]string{"d/a.txt": "a", "d/locked.txt": "locked", "d/z.txt": "z"})
+	afs.Mount("src", lockedDir{src}, true)
+	afs.Mount("dst", mem.NewDir(), true)
+	
+	if err := afs.Rename("src/d", "dst/d"); !errors.Is(err, axis2.ErrPermissionSentinel) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	
+	// Part of the original is gone, so the copy must still be complete.
+	for _, name := range []string{"a", "locked", "z"} {
+		if content, err := afs.ReadAll("dst/d/" + name + ".txt"); err != nil || string(content) != name {
+			t.Errorf("%v: copy damaged by failed rename: %q (%v)", name, content, err)
+		}
+	}
+	if !afs.Exists("src/d/locked.txt") {
+		t.Error("locked.txt deleted")
+	}
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+// Package iofs allows any io/fs.FS (embed.FS, fstest.MapFS, os.DirFS, etc) to be used as an AXIS DataSource.
+package iofs
+
+import "github.com/milochristiansen/axis2"
+
+import "errors"
+import "io"
+import "io/fs"
+import "os"
+
+// WriteFS is an fs.FS that also supports writing.
+// 
+// If the FS passed to NewDir implements WriteFS the resulting Dir is writable, otherwise any attempt to change it
+// returns an error of type ErrReadOnly.
+type WriteFS interface {
+	fs.FS
+	
+	// OpenFile opens the named file for writing using the given os.O_* flags (os.O_WRONLY is always included).
+	// If os.O_CREATE is given and the file does not exist it should be created, along with any missing parent
+	// directories.
+	OpenFile(name string, flag int, perm fs.FileMode) (io.WriteCloser, error)
+	
+	// Remove removes the named file or (empty) directory.
+	Remove(name string) error
+}
+
+// MkdirFS is a WriteFS that can also create empty directories.
+type MkdirFS interface {
+	WriteFS
+	
+	// Mkdir creates the named directory. The parent directory will always exist.
+	Mkdir(name string, perm fs.FileMode) error
+}
+
+type fsDir struct {
+	fsys fs.FS
+	name string
+}
+
+type fsFile struct {
+	fsys fs.FS
+	name string
+}
+
+// NewDir creates an AXIS Dir backed by the root of the given fs.FS.
+// The Dir is read-only unless fsys implements WriteFS.
+func NewDir(fsys fs.FS) axis2.Dir {
+	return fsDir{fsys: fsys, name: "."}
+}
+
+func (dir fsDir) join(id string) string {
+	if dir.name == "." {
+		return id
+	}
+	return dir.name + "/" + id
+}
+
+func (dir fsDir) Child(id string, create int) axis2.DataSource {
+	ds, _ := dir.FindChild(id, create)
+	return ds
+}
+
+func (dir fsDir) FindChild(id string, create int) (axis2.DataSource, error) {
+	name := dir.join(id)
+	
+	info, err := fs.Stat(dir.fsys, name)
+	if err == nil {
+		if info.IsDir() {
+			return fsDir{fsys: dir.fsys, name: name}, nil
+		}
+		return fsFile{fsys: dir.fsys, name: name}, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	
+	// Don't pretend we can create things if we can't.
+	if _, ok := dir.fsys.(WriteFS); !ok {
+		return nil, nil
+	}
+	switch create {
+	case axis2.CreateDir:
+		return fsDir{fsys: dir.fsys, name: name}, nil
+	case axis2.CreateFile:
+		return fsFile{fsys: dir.fsys, name: name}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func (dir fsDir) Stat() (fs.FileInfo, error) {
+	return fs.Stat(dir.fsys, dir.name)
+}
+
+func (dir fsDir) Delete(id string) error {
+	wfs, ok := dir.fsys.(WriteFS)
+	if !ok {
+		return axis2.NewError(axis2.ErrReadOnly)
+	}
+	return wfs.Remove(dir.join(id))
+}
+
+func (dir fsDir) Mkdir(id string) error {
+	switch fsys := dir.fsys.(type) {
+	case MkdirFS:
+		return fsys.Mkdir(dir.join(id), 0777)
+	case WriteFS:
+		return axis2.NewError(axis2.ErrUnsupported)
+	default:
+		return axis2.NewError(axis2.ErrReadOnly)
+	}
+}
+
+func (dir fsDir) List() []string {
+	entries, err := fs.ReadDir(dir.fsys, dir.name)
+	if err != nil {
+		return nil
+	}
+	
+	rtn := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		rtn = append(rtn, entry.Name())
+	}
+	return rtn
+}
+
+func (file fsFile) Size() int64 {
+	info, err := fs.Stat(file.fsys, file.name)
+	if err != nil {
+		return -1
+	}
+	return info.Size()
+}
+
+func (file fsFile) Stat() (fs.FileInfo, error) {
+	return fs.Stat(file.fsys, file.name)
+}
+
+func (file fsFile) Read() (io.ReadCloser, error) {
+	return file.fsys.Open(file.name)
+}
+
+// OpenRandom is supported if the fs.File returned by Open implements io.ReaderAt and io.Seeker.
+func (file fsFile) OpenRandom() (axis2.RandomReader, error) {
+	f, err := file.fsys.Open(file.name)
+	if err != nil {
+		return nil, err
+	}
+	if r, ok := f.(axis2.RandomReader); ok {
+		return r, nil
+	}
+	f.Close()
+	return nil, axis2.NewError(axis2.ErrUnsupported)
+}
+
+func (file fsFile) Write() (io.WriteCloser, error) {
+	wfs, ok := file.fsys.(WriteFS)
+	if !ok {
+		return nil, axis2.NewError(axis2.ErrReadOnly)
+	}
+	return wfs.OpenFile(file.name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+}
+
+func (file fsFile) Append() (io.WriteCloser, error) {
+	wfs, ok := file.fsys.(WriteFS)
+	if !ok {
+		return nil, axis2.NewError(axis2.ErrReadOnly)
+	}
+	return wfs.OpenFile(file.name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+}
@@ -0,0 +1,399 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+// Package mem provides an AXIS DataSource that lives entirely in memory.
+package mem
+
+import "github.com/milochristiansen/axis2"
+
+import "io"
+import "os"
+import "sort"
+import "sync"
+import "time"
+import "bytes"
+
+// Dir is a writable in-memory AXIS Dir. Every Dir and File in a tree shares a single lock, so a tree is safe for
+// concurrent use.
+// 
+// Items created with the CreateDir and CreateFile hints are not actually added to the tree until something is
+// written to them (or Mkdir is called).
+// 
+// Written data is not visible to readers until the writer is closed, and the contents of a File never change once
+// they are visible (new contents replace the old ones), so open readers see the contents as they were when the
+// File was opened.
+type Dir struct {
+	tree   *tree
+	parent *Dir // nil for the root
+	name   string
+	items  map[string]interface{} // Either *Dir or *file
+	perm   os.FileMode
+	mod    time.Time
+}
+
+type file struct {
+	tree   *tree
+	parent *Dir
+	name   string
+	data   []byte // Never modified after being set, replaced instead.
+	perm   os.FileMode
+	mod    time.Time
+}
+
+type tree struct {
+	lock sync.RWMutex
+}
+
+// NewDir creates a new empty in-memory tree and returns the root Dir.
+func NewDir() *Dir {
+	return newDir(&tree{}, nil, "")
+}
+
+func newDir(t *tree, parent *Dir, name string) *Dir {
+	return &Dir{
+		tree:   t,
+		parent: parent,
+		name:   name,
+		items:  map[string]interface{}{},
+		perm:   0777,
+		mod:    time.Now(),
+	}
+}
+
+// Clone returns a deep copy of the Dir as the root of a new tree, which may then be changed without effecting the
+// original. File contents are shared until they are replaced, so this is cheap even for large trees.
+// 
+// This is useful for taking a snapshot of a known state so it can be restored later (with SwapMount, for example).
+func (dir *Dir) Clone() *Dir {
+	dir.tree.lock.RLock()
+	defer dir.tree.lock.RUnlock()
+	
+	return dir.clone(&tree{}, nil)
+}
+
+func (dir *Dir) clone(t *tree, parent *Dir) *Dir {
+	rtn := &Dir{
+		tree:   t,
+		parent: parent,
+		name:   dir.name,
+		items:  make(map[string]interface{}, len(dir.items)),
+		perm:   dir.perm,
+		mod:    dir.mod,
+	}
+	for name, item := range dir.items {
+		switch item := item.(type) {
+		case *Dir:
+			rtn.items[name] = item.clone(t, rtn)
+		case *file:
+			nf := *item
+			nf.tree, nf.parent = t, rtn
+			rtn.items[name] = &nf
+		}
+	}
+	return rtn
+}
+
+// attach makes sure the Dir is part of the tree, adding it (and its parents) if needed. Returns the Dir that is actually
+// in the tree at this Dir's location, which may not be this Dir, or nil if there is a File in the way.
+// 
+// The tree must be locked for writing.
+func (dir *Dir) attach() *Dir {
+	if dir.parent == nil {
+		return dir
+	}
+	
+	parent := dir.parent.attach()
+	if parent == nil {
+		return nil
+	}
+	switch item := parent.items[dir.name].(type) {
+	case *Dir:
+		return item
+	case *file:
+		return nil
+	}
+	
+	dir.parent = parent
+	parent.items[dir.name] = dir
+	parent.mod = time.Now()
+	return dir
+}
+
+func (dir *Dir) Child(id string, create int) axis2.DataSource {
+	dir.tree.lock.RLock()
+	defer dir.tree.lock.RUnlock()
+	
+	if item, ok := dir.items[id]; ok {
+		return item
+	}
+	switch create {
+	case axis2.CreateDir:
+		return newDir(dir.tree, dir, id)
+	case axis2.CreateFile:
+		return &file{
+			tree:   dir.tree,
+			parent: dir,
+			name:   id,
+			perm:   0666,
+		}
+	default:
+		return nil
+	}
+}
+
+func (dir *Dir) Delete(id string) error {
+	dir.tree.lock.Lock()
+	defer dir.tree.lock.Unlock()
+	
+	item, ok := dir.items[id]
+	if !ok {
+		return axis2.NewError(axis2.ErrNotFound)
+	}
+	if d, ok := item.(*Dir); ok && len(d.items) != 0 {
+		return axis2.NewError(axis2.ErrNotEmpty)
+	}
+	delete(dir.items, id)
+	dir.mod = time.Now()
+	return nil
+}
+
+func (dir *Dir) List() []string {
+	dir.tree.lock.RLock()
+	defer dir.tree.lock.RUnlock()
+	
+	rtn := make([]string, 0, len(dir.items))
+	for name := range dir.items {
+		rtn = append(rtn, name)
+	}
+	sort.Strings(rtn)
+	return rtn
+}
+
+func (dir *Dir) Mkdir(id string) error {
+	dir.tree.lock.Lock()
+	defer dir.tree.lock.Unlock()
+	
+	if newDir(dir.tree, dir, id).attach() == nil {
+		return axis2.NewError(axis2.ErrNotDir)
+	}
+	return nil
+}
+
+func (dir *Dir) Rename(id string, to axis2.Dir, toid string) error {
+	tdir, ok := to.(*Dir)
+	if !ok || tdir.tree != dir.tree {
+		return axis2.NewError(axis2.ErrUnsupported)
+	}
+	
+	dir.tree.lock.Lock()
+	defer dir.tree.lock.Unlock()
+	
+	item, ok := dir.items[id]
+	if !ok {
+		return axis2.NewError(axis2.ErrNotFound)
+	}
+	tdir = tdir.attach()
+	if tdir == nil {
+		return axis2.NewError(axis2.ErrNotDir)
+	}
+	if _, ok := tdir.items[toid]; ok {
+		return axis2.NewError(axis2.ErrExists)
+	}
+	
+	// Make sure a directory isn't being moved inside itself.
+	if d, ok := item.(*Dir); ok {
+		for p := tdir; p != nil; p = p.parent {
+			if p == d {
+				return axis2.NewError(axis2.ErrBadAction)
+			}
+		}
+	}
+	
+	delete(dir.items, id)
+	switch item := item.(type) {
+	case *Dir:
+		item.parent, item.name = tdir, toid
+	case *file:
+		item.parent, item.name = tdir, toid
+	}
+	tdir.items[toid] = item
+	dir.mod, tdir.mod = time.Now(), time.Now()
+	return nil
+}
+
+func (dir *Dir) Stat() (os.FileInfo, error) {
+	dir.tree.lock.RLock()
+	defer dir.tree.lock.RUnlock()
+	
+	return &info{name: dir.name, mode: os.ModeDir | dir.perm, mod: dir.mod}, nil
+}
+
+func (dir *Dir) SetMeta(perm os.FileMode, modTime time.Time) error {
+	dir.tree.lock.Lock()
+	defer dir.tree.lock.Unlock()
+	
+	dir.perm = perm.Perm()
+	if !modTime.IsZero() {
+		dir.mod = modTime
+	}
+	return nil
+}
+
+func (f *file) Size() int64 {
+	f.tree.lock.RLock()
+	defer f.tree.lock.RUnlock()
+	
+	return int64(len(f.data))
+}
+
+func (f *file) Read() (io.ReadCloser, error) {
+	f.tree.lock.RLock()
+	defer f.tree.lock.RUnlock()
+	
+	return io.NopCloser(bytes.NewReader(f.data)), nil
+}
+
+func (f *file) OpenRandom() (axis2.RandomReader, error) {
+	f.tree.lock.RLock()
+	defer f.tree.lock.RUnlock()
+	
+	return nopCloser{bytes.NewReader(f.data)}, nil
+}
+
+func (f *file) Write() (io.WriteCloser, error) {
+	return &writer{f: f}, nil
+}
+
+func (f *file) Append() (io.WriteCloser, error) {
+	return &writer{f: f, append: true}, nil
+}
+
+func (f *file) Stat() (os.FileInfo, error) {
+	f.tree.lock.RLock()
+	defer f.tree.lock.RUnlock()
+	
+	return &info{name: f.name, size: int64(len(f.data)), mode: f.perm, mod: f.mod}, nil
+}
+
+func (f *file) SetMeta(perm os.FileMode, modTime time.Time) error {
+	f.tree.lock.Lock()
+	defer f.tree.lock.Unlock()
+	
+	f.perm = perm.Perm()
+	if !modTime.IsZero() {
+		f.mod = modTime
+	}
+	return nil
+}
+
+// writer buffers written data until it is closed, then replaces (or appends to) the contents of its File.
+type writer struct {
+	f      *file
+	buf    bytes.Buffer
+	append bool
+	closed bool
+}
+
+func (w *writer) Write(b []byte) (int, error) {
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	return w.buf.Write(b)
+}
+
+func (w *writer) Close() error {
+	if w.closed {
+		return os.ErrClosed
+	}
+	w.closed = true
+	
+	f := w.f
+	f.tree.lock.Lock()
+	defer f.tree.lock.Unlock()
+	
+	parent := f.parent.attach()
+	if parent == nil {
+		return axis2.NewError(axis2.ErrNotDir)
+	}
+	if _, ok := parent.items[f.name].(*Dir); ok {
+		return axis2.NewError(axis2.ErrIsDir)
+	}
+	if existing, ok := parent.items[f.name].(*file); ok {
+		// Someone else may have created the File since this one was returned by Child.
+		f = existing
+	} else {
+		f.parent = parent
+		parent.items[f.name] = f
+		parent.mod = time.Now()
+	}
+	
+	if w.append {
+		data := make([]byte, 0, len(f.data)+w.buf.Len())
+		data = append(data, f.data...)
+		f.data = append(data, w.buf.Bytes()...)
+	} else {
+		f.data = append([]byte(nil), w.buf.Bytes()...)
+	}
+	f.mod = time.Now()
+	return nil
+}
+
+// nopCloser adds a Close method that does nothing to a bytes.Reader.
+type nopCloser struct {
+	*bytes.Reader
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// info is the os.FileInfo for both Dirs and Files.
+type info struct {
+	name string
+	size int64
+	mode os.FileMode
+	mod  time.Time
+}
+
+func (i *info) Name() string {
+	return i.name
+}
+
+func (i *info) Size() int64 {
+	return i.size
+}
+
+func (i *info) Mode() os.FileMode {
+	return i.mode
+}
+
+func (i *info) ModTime() time.Time {
+	return i.mod
+}
+
+func (i *info) IsDir() bool {
+	return i.mode.IsDir()
+}
+
+func (i *info) Sys() interface{} {
+	return nil
+}
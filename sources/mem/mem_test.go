@@ -0,0 +1,224 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package mem_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"testing/fstest"
+	
+	"github.com/milochristiansen/axis2"
+	"github.com/milochristiansen/axis2/sources/mem"
+)
+
+func mount(root *mem.Dir) *axis2.FileSystem {
+	afs := new(axis2.FileSystem)
+	afs.Mount("", root, true)
+	return afs
+}
+
+func TestIOFSMem(t *testing.T) {
+	root := mem.NewDir()
+	afs := mount(root)
+	
+	for _, name := range []string{"a/x.txt", "a/b/y.txt", "z.txt"} {
+		if err := afs.WriteAll(name, []byte(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := afs.Mkdir("empty"); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := root.Clone()
+	
+	err := fstest.TestFS(axis2.NewIOFS(afs), "a/x.txt", "a/b/y.txt", "z.txt", "empty")
+	if err != nil {
+		t.Fatal(err)
+	}
+	
+	// Changes must not leak into the snapshot.
+	if err := afs.RemoveAll("a"); err != nil {
+		t.Fatal(err)
+	}
+	w, err := afs.Append("z.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("!"))
+	w.Close()
+	
+	afs.SwapMount("", snapshot, true)
+	if !afs.Exists("a/b/y.txt") {
+		t.Error("snapshot lost a/b/y.txt")
+	}
+	if content, _ := afs.ReadAll("z.txt"); string(content) != "z.txt" {
+		t.Errorf("snapshot has unexpected z.txt contents: %q", content)
+	}
+}
+
+func TestClone(t *testing.T) {
+	root := mem.NewDir()
+	afs := mount(root)
+	for _, name := range []string{"shared.txt", "a/x.txt", "gone.txt"} {
+		if err := afs.WriteAll(name, []byte(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	
+	clone := root.Clone()
+	cfs := mount(clone)
+	
+	// Change both sides.
+	if err := afs.WriteAll("shared.txt", []byte("original")); err != nil {
+		t.Fatal(err)
+	}
+	if err := afs.WriteAll("a/original.txt", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := afs.Delete("gone.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfs.WriteAll("shared.txt", []byte("clone")); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfs.WriteAll("a/clone.txt", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfs.Rename("a/x.txt", "x.txt"); err != nil {
+		t.Fatal(err)
+	}
+	
+	if content, _ := afs.ReadAll("shared.txt"); string(content) != "original" {
+		t.Errorf("original has unexpected shared.txt contents: %q", content)
+	}
+	if content, _ := cfs.ReadAll("shared.txt"); string(content) != "clone" {
+		t.Errorf("clone has unexpected shared.txt contents: %q", content)
+	}
+	if afs.Exists("a/clone.txt") || afs.Exists("x.txt") || !afs.Exists("a/x.txt") || afs.Exists("gone.txt") {
+		t.Errorf("changes to the clone leaked into the original: %v %v", afs.List(""), afs.List("a"))
+	}
+	if cfs.Exists("a/original.txt") || cfs.Exists("a/x.txt") || !cfs.Exists("x.txt") || !cfs.Exists("gone.txt") {
+		t.Errorf("changes to the original leaked into the clone: %v %v", cfs.List(""), cfs.List("a"))
+	}
+}
+
+func TestRename(t *testing.T) {
+	root := mem.NewDir()
+	afs := mount(root)
+	for _, name := range []string{"a/x.txt", "a/b/y.txt", "c/z.txt"} {
+		if err := afs.WriteAll(name, []byte(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dir := func(path string) *mem.Dir {
+		ds, err := afs.GetDSAt(path, false, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ds.(*mem.Dir)
+	}
+	
+	if err := root.Rename("a", dir("c"), "a2"); err != nil {
+		t.Fatal(err)
+	}
+	if afs.Exists("a") || !afs.Exists("c/a2/b/y.txt") {
+		t.Errorf("rename failed: %v", afs.List("c"))
+	}
+	if content, _ := afs.ReadAll("c/a2/x.txt"); string(content) != "a/x.txt" {
+		t.Errorf("unexpected contents: %q", content)
+	}
+	
+	// Renaming into a Dir that doesn't exist yet creates it.
+	if err := dir("c").Rename("z.txt", root.Child("new", axis2.CreateDir).(axis2.Dir), "z.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if !afs.Exists("new/z.txt") {
+		t.Error("rename into new Dir failed")
+	}
+	
+	tests := []struct {
+		from, id string
+		to       axis2.Dir
+		toid     string
+		want     error
+	}{
+		{"c", "missing", root, "x", axis2.ErrNotFoundSentinel},
+		{"c", "a2", root, "new", axis2.ErrExistsSentinel},
+		{"c", "a2", dir("c/a2/b"), "loop", axis2.ErrBadActionSentinel},
+		{"c", "a2", mem.NewDir(), "x", axis2.ErrUnsupportedSentinel},
+	}
+	for _, test := range tests {
+		if err := dir(test.from).Rename(test.id, test.to, test.toid); !errors.Is(err, test.want) {
+			t.Errorf("rename %v/%v to %v: got %v, want %v", test.from, test.id, test.toid, err, test.want)
+		}
+	}
+}
+
+// TestConcurrent is mostly useful with the race detector.
+func TestConcurrent(t *testing.T) {
+	root := mem.NewDir()
+	afs := mount(root)
+	
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for k := 0; k < 50; k++ {
+				name := fmt.Sprintf("d%v/f%v.txt", k%4, i)
+				if err := afs.WriteAll(name, []byte(name)); err != nil {
+					t.Error(err)
+					return
+				}
+				w, err := afs.Append(name)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				w.Write([]byte("!"))
+				w.Close()
+				
+				if content, err := afs.ReadAll(name); err != nil || string(content) != name+"!" {
+					t.Errorf("%v: unexpected contents %q: %v", name, content, err)
+				}
+				afs.List(fmt.Sprintf("d%v", k%4))
+				afs.Stat(name)
+				if k%3 == 0 {
+					root.Clone()
+				}
+				if err := afs.Delete(name); err != nil {
+					t.Error(err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	
+	for _, name := range afs.List("") {
+		if files := afs.List(name); len(files) != 0 {
+			t.Errorf("%v not empty: %v", name, files)
+		}
+	}
+}
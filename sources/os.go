@@ -26,6 +26,8 @@ import ospath "path"
 import "os"
 import "io"
 import "io/ioutil"
+import "path/filepath"
+import "time"
 
 import "github.com/milochristiansen/axis2"
 
@@ -46,12 +48,33 @@ func (file osFile) Size() int64 {
 	return s.Size()
 }
 
+func (file osFile) Stat() (os.FileInfo, error) {
+	path := string(file)
+	
+	return os.Stat(path)
+}
+
+func (file osFile) SetMeta(perm os.FileMode, modTime time.Time) error {
+	return setMeta(string(file), perm, modTime)
+}
+
+// Describe returns the absolute OS path of the file.
+func (file osFile) Describe() string {
+	return absPath(string(file))
+}
+
 func (file osFile) Read() (io.ReadCloser, error) {
 	path := string(file)
 	
 	return os.Open(path)
 }
 
+func (file osFile) OpenRandom() (axis2.RandomReader, error) {
+	path := string(file)
+	
+	return os.Open(path)
+}
+
 func (file osFile) Write() (io.WriteCloser, error) {
 	path := string(file)
 	
@@ -90,31 +113,73 @@ func NewOSDir(path string) axis2.Dir {
 }
 
 func (dir osDir) Child(id string, create int) axis2.DataSource {
+	ds, _ := dir.FindChild(id, create)
+	return ds
+}
+
+func (dir osDir) FindChild(id string, create int) (axis2.DataSource, error) {
 	path := string(dir) + "/" + id
 	
 	info, err := os.Stat(path)
 	if err == nil {
 		if info.IsDir() {
-			return osDir(path)
+			return osDir(path), nil
 		}
-		return osFile(path)
+		return osFile(path), nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
 	}
 	switch create {
 	case axis2.CreateDir:
-		return osDir(path)
+		return osDir(path), nil
 	case axis2.CreateFile:
-		return osFile(path)
+		return osFile(path), nil
 	default:
-		return nil
+		return nil, nil
 	}
 }
 
+// Describe returns the absolute OS path of the directory.
+func (dir osDir) Describe() string {
+	return absPath(string(dir))
+}
+
+func (dir osDir) Stat() (os.FileInfo, error) {
+	path := string(dir)
+	
+	return os.Stat(path)
+}
+
+func (dir osDir) SetMeta(perm os.FileMode, modTime time.Time) error {
+	return setMeta(string(dir), perm, modTime)
+}
+
 func (dir osDir) Delete(id string) error {
 	path := string(dir)
 	
 	return os.Remove(path + "/" + id)
 }
 
+func (dir osDir) Mkdir(id string) error {
+	path := string(dir)
+	
+	return os.Mkdir(path+"/"+id, 0777)
+}
+
+func (dir osDir) Rename(id string, to axis2.Dir, toid string) error {
+	tdir, ok := to.(osDir)
+	if !ok {
+		return axis2.NewError(axis2.ErrUnsupported)
+	}
+	
+	err := os.MkdirAll(string(tdir), 0777)
+	if err != nil {
+		return err
+	}
+	return os.Rename(string(dir)+"/"+id, string(tdir)+"/"+toid)
+}
+
 func (dir osDir) List() []string {
 	path := string(dir)
 	
@@ -130,3 +195,19 @@ func (dir osDir) List() []string {
 	return rtn
 }
 
+func setMeta(path string, perm os.FileMode, modTime time.Time) error {
+	err := os.Chmod(path, perm)
+	if err != nil || modTime.IsZero() {
+		return err
+	}
+	return os.Chtimes(path, modTime, modTime)
+}
+
+// absPath returns the absolute version of path, or path itself if that is not possible.
+func absPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	return abs
+}
@@ -0,0 +1,357 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+// Package tar provides read-only AXIS DataSources backed by tar archives, optionally compressed with gzip, bzip2, or xz.
+package tar
+
+import "github.com/milochristiansen/axis2"
+
+import "io"
+import "os"
+import "time"
+import "bufio"
+import "bytes"
+import "strings"
+import "archive/tar"
+import "compress/gzip"
+import "compress/bzip2"
+
+type tdir struct {
+	items map[string]interface{} // Either *tdir or *tfile
+	name  string
+	hdr   *tar.Header // nil if the directory has no entry of its own
+}
+
+type tfile struct {
+	hdr *tar.Header
+	
+	// If data is nil the contents are read from r starting at offset.
+	r      io.ReaderAt
+	offset int64
+	data   []byte
+}
+
+// NewDir creates a read-only AXIS Dir backed by a tar file.
+// 
+// If the tar file is not compressed the archive is indexed without reading the contents of the files, and each
+// file is read directly from the given io.ReaderAt when opened. If the tar file is compressed (with gzip, bzip2, or
+// xz) this is the same as calling NewStreamDir.
+func NewDir(file io.ReaderAt, size int64) (axis2.Dir, error) {
+	magic := make([]byte, 6)
+	n, _ := file.ReadAt(magic, 0)
+	if compressed(magic[:n]) {
+		return NewStreamDir(io.NewSectionReader(file, 0, size))
+	}
+	
+	sr := io.NewSectionReader(file, 0, size)
+	return mkTree(tar.NewReader(sr), func(tr *tar.Reader, hdr *tar.Header) (*tfile, error) {
+		// Sparse files are not stored contiguously, so they need to be read into memory.
+		if sparse(hdr) {
+			return cache(tr, hdr)
+		}
+		
+		// The reader is always positioned at the start of the file's data after tar.Reader.Next returns.
+		offset, err := sr.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+		return &tfile{hdr: hdr, r: file, offset: offset}, nil
+	})
+}
+
+// NewRawDir creates a read-only AXIS Dir backed by a (possibly compressed) tar file that has been read into memory.
+func NewRawDir(content []byte) (axis2.Dir, error) {
+	return NewDir(bytes.NewReader(content), int64(len(content)))
+}
+
+// NewStreamDir creates a read-only AXIS Dir from a tar stream that does not support random access, generally because
+// it is compressed. gzip, bzip2, and xz compression is detected and handled automatically (xz streams must use the
+// LZMA2 filter, which is the default).
+// 
+// The stream is read exactly once, and the contents of every file are cached in memory.
+func NewStreamDir(r io.Reader) (axis2.Dir, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(6)
+	
+	var tr *tar.Reader
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer gr.Close()
+		tr = tar.NewReader(gr)
+	case bytes.HasPrefix(magic, []byte("BZh")):
+		tr = tar.NewReader(bzip2.NewReader(br))
+	case bytes.HasPrefix(magic, xzMagic):
+		xr, err := newXZReader(br)
+		if err != nil {
+			return nil, err
+		}
+		
+		// The tar reader stops at the end of archive marker, so the rest of the stream (and its index and checks)
+		// has to be read explicitly to catch corruption.
+		dir, err := mkTree(tar.NewReader(xr), cache)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(io.Discard, xr); err != nil {
+			return nil, err
+		}
+		return dir, nil
+	default:
+		tr = tar.NewReader(br)
+	}
+	return mkTree(tr, cache)
+}
+
+func sparse(hdr *tar.Header) bool {
+	if hdr.Typeflag == tar.TypeGNUSparse {
+		return true
+	}
+	for key := range hdr.PAXRecords {
+		if strings.HasPrefix(key, "GNU.sparse.") {
+			return true
+		}
+	}
+	return false
+}
+
+func compressed(magic []byte) bool {
+	return bytes.HasPrefix(magic, []byte{0x1f, 0x8b}) ||
+		bytes.HasPrefix(magic, []byte("BZh")) ||
+		bytes.HasPrefix(magic, xzMagic)
+}
+
+// cache reads the contents of the current file into memory.
+func cache(tr *tar.Reader, hdr *tar.Header) (*tfile, error) {
+	data, err := io.ReadAll(tr)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		data = []byte{}
+	}
+	return &tfile{hdr: hdr, data: data}, nil
+}
+
+// Like zip files, tar files are assumed readonly, so I generate a static tree of dir and file objects when opening
+// the tar file. The tree is built in one pass, with mkfile deciding how each file's contents are accessed.
+func mkTree(tr *tar.Reader, mkfile func(tr *tar.Reader, hdr *tar.Header) (*tfile, error)) (*tdir, error) {
+	base := &tdir{
+		items: map[string]interface{}{},
+	}
+	
+	next:
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return base, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		
+		parts := split(hdr.Name)
+		if len(parts) == 0 {
+			continue
+		}
+		for _, part := range parts {
+			if part == ".." {
+				continue next
+			}
+		}
+		
+		dir := base
+		for i := 0; i < len(parts)-1; i++ {
+			child, ok := dir.items[parts[i]].(*tdir)
+			if !ok {
+				child = &tdir{
+					items: map[string]interface{}{},
+					name: parts[i],
+				}
+				dir.items[parts[i]] = child
+			}
+			dir = child
+		}
+		name := parts[len(parts)-1]
+		
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			// Don't clobber the directory if one of its children was listed first.
+			if child, ok := dir.items[name].(*tdir); ok {
+				child.hdr = hdr
+				continue
+			}
+			dir.items[name] = &tdir{
+				items: map[string]interface{}{},
+				name: name,
+				hdr: hdr,
+			}
+		case tar.TypeReg, tar.TypeGNUSparse:
+			file, err := mkfile(tr, hdr)
+			if err != nil {
+				return nil, err
+			}
+			dir.items[name] = file
+		case tar.TypeLink:
+			// Hard links share the contents of a file that appeared earlier in the archive.
+			target, ok := base.find(split(hdr.Linkname)).(*tfile)
+			if !ok {
+				continue
+			}
+			link := *hdr
+			link.Size = target.hdr.Size
+			file := *target
+			file.hdr = &link
+			dir.items[name] = &file
+		default:
+			// Symbolic links, devices, etc are not supported.
+		}
+	}
+}
+
+// split splits a path from a tar header into its parts, removing any empty or "." parts.
+func split(name string) []string {
+	var parts []string
+	for _, part := range strings.Split(name, "/") {
+		if part != "" && part != "." {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+func (dir *tdir) find(parts []string) interface{} {
+	var item interface{} = dir
+	for _, part := range parts {
+		d, ok := item.(*tdir)
+		if !ok {
+			return nil
+		}
+		item = d.items[part]
+	}
+	return item
+}
+
+func (dir *tdir) Child(id string, create int) axis2.DataSource {
+	return dir.items[id]
+}
+
+func (dir *tdir) Stat() (os.FileInfo, error) {
+	if dir.hdr != nil {
+		return dir.hdr.FileInfo(), nil
+	}
+	return dirInfo(dir.name), nil
+}
+
+func (dir *tdir) Delete(id string) error {
+	return axis2.NewError(axis2.ErrReadOnly)
+}
+
+func (dir *tdir) Mkdir(id string) error {
+	return axis2.NewError(axis2.ErrReadOnly)
+}
+
+func (dir *tdir) List() []string {
+	var rtn []string
+	for n := range dir.items {
+		rtn = append(rtn, n)
+	}
+	return rtn
+}
+
+func (file *tfile) Size() int64 {
+	if file.data != nil {
+		return int64(len(file.data))
+	}
+	return file.hdr.Size
+}
+
+func (file *tfile) Stat() (os.FileInfo, error) {
+	return file.hdr.FileInfo(), nil
+}
+
+func (file *tfile) Read() (io.ReadCloser, error) {
+	if file.data != nil {
+		return io.NopCloser(bytes.NewReader(file.data)), nil
+	}
+	return io.NopCloser(io.NewSectionReader(file.r, file.offset, file.hdr.Size)), nil
+}
+
+func (file *tfile) OpenRandom() (axis2.RandomReader, error) {
+	if file.data != nil {
+		return nopCloser{bytes.NewReader(file.data)}, nil
+	}
+	return nopCloser{io.NewSectionReader(file.r, file.offset, file.hdr.Size)}, nil
+}
+
+func (file *tfile) Write() (io.WriteCloser, error) {
+	return nil, axis2.NewError(axis2.ErrReadOnly)
+}
+
+func (file *tfile) Append() (io.WriteCloser, error) {
+	return nil, axis2.NewError(axis2.ErrReadOnly)
+}
+
+// nopCloser adds a Close method that does nothing to a bytes.Reader or io.SectionReader.
+type nopCloser struct {
+	readSeekerAt
+}
+
+type readSeekerAt interface {
+	io.Reader
+	io.ReaderAt
+	io.Seeker
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// dirInfo is the os.FileInfo for directories that do not have an entry of their own.
+type dirInfo string
+
+func (info dirInfo) Name() string {
+	return string(info)
+}
+
+func (info dirInfo) Size() int64 {
+	return 0
+}
+
+func (info dirInfo) Mode() os.FileMode {
+	return os.ModeDir | 0777
+}
+
+func (info dirInfo) ModTime() time.Time {
+	return time.Time{}
+}
+
+func (info dirInfo) IsDir() bool {
+	return true
+}
+
+func (info dirInfo) Sys() interface{} {
+	return nil
+}
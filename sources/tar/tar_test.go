@@ -0,0 +1,264 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package tar_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"testing"
+	
+	"github.com/milochristiansen/axis2"
+	axistar "github.com/milochristiansen/axis2/sources/tar"
+)
+
+// countingReaderAt counts the bytes read from a ReaderAt.
+type countingReaderAt struct {
+	r io.ReaderAt
+	n int
+}
+
+func (c *countingReaderAt) ReadAt(b []byte, off int64) (int, error) {
+	n, err := c.r.ReadAt(b, off)
+	c.n += n
+	return n, err
+}
+
+// expected reads the test archive with archive/tar, returning the contents of every file.
+func expected(t *testing.T) map[string][]byte {
+	rtn := map[string][]byte{}
+	tr := tar.NewReader(bytes.NewReader(rawTar))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return rtn
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		
+		name := hdr.Name[2:] // Strip the leading "./"
+		switch hdr.Typeflag {
+		case tar.TypeReg, tar.TypeGNUSparse:
+			content, err := io.ReadAll(tr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rtn[name] = content
+		case tar.TypeLink:
+			rtn[name] = rtn[hdr.Linkname[2:]]
+		}
+	}
+}
+
+// check makes sure every file in the test archive can be read from ds.
+func check(t *testing.T, ds axis2.DataSource) {
+	t.Helper()
+	
+	afs := new(axis2.FileSystem)
+	afs.Mount("", ds, false)
+	for name, want := range expected(t) {
+		content, err := afs.ReadAll(name)
+		if err != nil {
+			t.Errorf("%v: %v", name, err)
+			continue
+		}
+		if !bytes.Equal(content, want) {
+			t.Errorf("%v: unexpected contents", name)
+		}
+		if afs.Size(name) != int64(len(want)) {
+			t.Errorf("%v: unexpected size %v, want %v", name, afs.Size(name), len(want))
+		}
+	}
+	if !afs.IsDir("a") {
+		t.Error("a is not a directory")
+	}
+	
+	// Sanity checks, in case expected is wrong.
+	if content, _ := afs.ReadAll("link.txt"); string(content) != "b.txt" {
+		t.Errorf("unexpected hard link contents: %q", content)
+	}
+	sparse, _ := afs.ReadAll("sparse.bin")
+	if len(sparse) != 1<<20 || string(sparse[300000:300006]) != "middle" || string(sparse[len(sparse)-3:]) != "end" {
+		t.Fatal("unexpected sparse file contents")
+	}
+	if sparse[0] != 0 || sparse[500000] != 0 {
+		t.Error("sparse file holes are not zero")
+	}
+	lines, _ := afs.ReadAll("lines.txt")
+	if !bytes.HasPrefix(lines, []byte("line 0\nline 1\n")) || !bytes.HasSuffix(lines, []byte("line 299\n")) {
+		t.Error("unexpected lines.txt contents")
+	}
+}
+
+func TestIndexed(t *testing.T) {
+	ra := &countingReaderAt{r: bytes.NewReader(rawTar)}
+	ds, err := axistar.NewDir(ra, int64(len(rawTar)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, ds)
+	
+	// Only the File that is opened is read.
+	want := expected(t)
+	afs := new(axis2.FileSystem)
+	afs.Mount("", ds, false)
+	ra.n = 0
+	content, err := afs.ReadAll("lines.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(content, want["lines.txt"]) || ra.n != len(content) {
+		t.Errorf("read %v bytes for a %v byte file", ra.n, len(content))
+	}
+	
+	ra.n = 0
+	r, err := afs.OpenRandom("random.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	buf := make([]byte, 10)
+	if _, err := r.ReadAt(buf, 1000); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf, want["random.bin"][1000:1010]) || ra.n != 10 {
+		t.Errorf("unexpected random access result")
+	}
+}
+
+func TestCompressed(t *testing.T) {
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write(rawTar)
+	w.Close()
+	
+	tests := map[string][]byte{
+		"gzip": gz.Bytes(),
+		"bzip2": tarBZ2,
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			ds, err := axistar.NewRawDir(content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			check(t, ds)
+			
+			ds, err = axistar.NewStreamDir(bytes.NewReader(content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			check(t, ds)
+		})
+	}
+}
+
+func mustDecode(s string) []byte {
+	content, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return content
+}
+
+// The test archive was created with GNU tar (using the GNU format, so sparse.bin is stored as a sparse file) and
+// contains:
+//	a/x.txt
+//	b.txt
+//	lines.txt  (300 numbered lines)
+//	link.txt   (a hard link to b.txt)
+//	random.bin (2048 random bytes)
+//	sparse.bin (1MB of zeros, with "middle" at 300000 and "end" at the end)
+// 
+// tarBZ2 is the archive compressed with bzip2.
+var (
+	tarBZ2 = mustDecode(`
+QlpoOTFBWSZTWcrq6MAAAsX/////////////////////////////////////////////4AkfHeAnd5Hp
+VQFb3mmnjnX22907sau2KlIMjTGo08o00HqPUNGQekaYmEeiPU09CY1NHqepoeoZqG0gG1Gm0ymmmnog
+ehDTQaDTNIeoGZRp6TaTaj0TyTGEnkyh6anknoNJowmm1B6TaQZU9E2o2po09CY01PRpoh6aENGTIyMa
+RpkyD0gaMTTAnoRtJtJ6mRiaZkxE0ek8pgBkMjKempiZNMaaaIaYmI00Mm0hkyaaPSYgMJ5IKqNT0I9J
+6Tymh6m1DyTRmU09NT1PSGT0jaTT0jaanpHpGj1NjVPUemp5I2ozU9Ro0DIM9Uyeo0aD1DTTynqaeKPU
+NMQA9TTIepoGRiGmnqeo9QNNDI2p6QwgVT3qKe1P9Uoj0KZR5qjQD1AAANAAADaQBoaNAAAHqAANAAAA
+DQeoDagABoAaAAABoGgABoAEAEwT0mACYRgAA0AAJpgAAAAAAAIwAJgTTAAA0AADQABoAAAANBMJgCYA
+AkmqJqbUamk9P1SaaGQ9QMQxGJo0MQepoDQAGmjQBoaABoND1DQD1D1BptRp6mmmg9T1B6mjQAAaGg0D
+1NNAAANqAA0DSU+QAccWQjCG7YbEEKVKYVdDIRWQBwkAjI1ASRVIgBB7UAbziawxeJqlMigmRMQ0ipCp
+ILwUqeLllptIhAYMDfOfz0qEKzseV0XjW2gy12RbZq+9HisbsGA6qnOAAAWlMIA/dxLOki9Glw+8P+wX
+Hi109dc23h4wJjk4KxHgPRVBCCQA+utCqGrx5Rhd7PBwcnkclv/EZDB1fJ32c5Zax3sY7pL+8oc/AxWn
+Yq/xN/ndA8AAGBAAEgIAAVQCIARACqAQAAkAgABgeAAFA4AAAYcIAAoHgAAwIAAkBAACqARACIAVQCAA
+EgEAAMDwAAoHAAAEgIAAVQCIARACqAQAAkAgABgeAAFA4AAA/3VNtw+y3uN6r2fPym94HyeDTs/t2dY9
+3K8H+/J7CteoUPTsbsV16SZ9LZWRGFuDj5ILe8Y14bIZy4ZFcFlJkbnsacK65ltClgYYKnJ1CKRTiCU7
+zuzFLoKTvy2vPpPnLvvj/CiiwaN9AAEiVwRtFa10dRM8/L++nhKmacBvX7R+xg9DX4Mex+Aab4dVWoPG
+77nsYryopgjwzGXyLnObxjPi0UQDsgACAAADpiwFqRCQJKCEkKPt2rC089W+74nhYe9cDlcnisB1thbd
+Lb5ObP7vfLnL4MW8FFNwK2c+QooUozmqtRX1zEmdMlpsxlIVyCpxU5DpH3m8Y5hk0fTxaWd5aAidZrWR
+YbqxkYQGtj60vjcBtHKgjMBShMH1rHvrbBGtAx1AUHp38afCE0DK00xsAECi8rEufR/vK/NIGRgKR6Gf
+6veAzCL3h+hkECzdJ0i4m1eq5G4t+7npzxPPPPPPPNNNNNNNzNWiUCBCKurYp4ARIpZe9oz8eyWVzY4L
+G5mtKaQryBFbdmJjbd5b0oBuIghtxHKXXkYnltIoodRWr1reW27eXTnN05znOc5SlKUpTg8FknQVZ9Rz
+31HEsOLxKySCWRxCq5XLApYCSJJHSAgJYRESwIcNtKIUQ4E4CIcCHDgQ4cCOT2PEUqqJPppnTcU0lA4A
+AuGZmZmKB9L6jnQfB7qz3OdCCSG2kNtIbacAQ2kNtIbaQ20iywvW4zr6KKLCsWLFixYGB4ADUszMza5N
+0xVHOSSSSbbG6ySSSmBJJJpckkjbyuy53qf3yEny114FW1KlEslK70UUTzAIAGayzMzXOk4+vXnj7t0Y
+xSSSrOqQhBJJKlznQhBJJLctMyvm+ZiMR9+Z5Cbr2m8dzdmzZlblFrdu2VQCAAzNWZmbfpum15CEHp6S
+VEIQodCEEknJJJJX2abzhXf+WbU5wtWrmrc2rREAIgMzNYZm0Gc5JJJJIc50IQzB1QjGKclXrRjGu5zo
+QgktavsDi7zCwOducvpuLi5s2Gu5Xd2QAAqgzMzYZmCMnOdKMYwhCCSSSSunQhBJTrRjFJZTxsbuGLvf
+z+7I169e4urq1aumuhAAEhmZmbDNus5ytttttttttttttu/5S73V+sfX46pPFFSpRRRRRRVTiAAJSlKU
+pM00kkkkkkkkkkknvW+YzD4tS2tBwC1viLibCPZCiK73DSsAO7YoYTTs8ERcjxucQ5X3kOqLBKsvGT5t
+IRrGOgphDfbDnJW8YoJIeV+yQhE4v4xcRGut2JDfK9eacLB6B1NkAt9KqiaPOZSnOdQStoxBDvyPuZ+i
+81X97UsgMAe/zIR7+gk67Pa2ton5v3GvgCN+XP6Pcr0GGaemia3VvJkOU0xP81iGohvvzpJI+eWslzDi
+I6OzaEpscf8Em0d2fhRh3np6Z+OMm4vYcpFQIl0E+z2jd4E1jawwGHq0kjqDM1VJ4+siLeAthXmuQwGG
+xOyozdxWRGEz8PFg7AXU3HCIv9W7LUIpwlSl0QXyiDuvXVL75w2CvhYkooWxVt9iT3ITpLoPe454aOox
+TAOxr4U6yb0mbeLzNEbuD9pXkuI753GOa48IJ1ePhowe9KeMWoAAFJsInGd6g11fMhgBsbYy6wCpMJvZ
+WtmOsOZ5E/DukMXATWgLvB1NgNnjjzyADs7AdgATu72LIXxb7Vv9kLRPCq9KeVL1iE69MBUfiSwmZ2kU
+pUYSnm4gNCf5B3FnDA7gCCCAEAGi35+pLJM6iqy2TEUzJ5zSpfqbNjHJMyMH50IIAQAgAfwXhvQ07wfq
+8KG+VoHILb2diIkG2/m2ZjQUgSEG6QZrtNvp2mh42v3oAvYBwHYfPdX/HZvTtwzn4cvmb7Yfto/z4rHa
+kBnPqw1nlAMIBNZ+5kuYyXmYHH+xqP45e/V33AW1p5OPubXBIPkRCUFhjMCBYpenr2d8H/ekDGQtJo9Q
+0V6tfstLiv1yyvmQ5yvP4yyVvp+hF0fHcCXhyAMe9wR4iUCIPyUPOZ+AVUGYQgUtCzSEmy4cufCg77va
+BQVDjM/fPu/Vd01Pnwt2x3M0DzNvTWyDAwGO8/X9PQad0rCCJXR2BBxJmUYW8Orrhkf7e1NzvQxc3upy
+OIS9+tjFPbr2epSSfnNW92sVTuwsjISizyS+tlNY0MXGl47/FU0dV0mjgfcYaBjLJJUk5ClKrWIWuQto
+GfHvOv2+uRKNKI3EaJfq3sJ9vQ5eqMl6vZOlEUkusivHyLvG4LUNGiCRlZxVL/SAgikUCk1nB63QszLx
+bOpMz+C93LUioad7onZqAwQjgPD+gsulMDGtnuGadgN4gf8/F5ZSyy9sbrHApNPyB2ruplejci/3fSSQ
+QFU1kaFElPBByT5zlAsZMicJDiutsyoHbA1yyoIB5ozOSiaELVJYhcedwllX3zGjpISVVmB2m2c5Vyx0
+uSx3r9BJ2MAiNfAu9Jf4Cy+mi5bTT+wg/JnAFCY5W4zzBSdVlNqI16qWQwRt/Lwb3nNgHiPbKTxyRZmh
+yCwkfj4luGUkHQh0Yx1ob5Oq7lL9k6fU+GBHQ4fsYP9rCC1uu7qlJH6pXa7WVZUOWoKE43f0zyu0XSU2
+YqxxS/h4kahqiNKLQwuEvL2uLGDzGlKI2qyezfMLjY6Ni9744JLE9apyXGSG3brWWsvzw5DVFlMyahiq
+6o3VciI09zYoeuLarx9VCcq6EtXZTnP9kodRd9rZbflyydxM7KzlXweL4qZLc+QoYnsUdrKQZlLjDO+B
+ytIB13Sim8Clq2EqDGlliDRy/BRUkMWXsWyVxQ4E4pZGHtLfo/JO8GNrJmhiZYYWuODj/XZ5q0vyH4sB
+L75Eqed3eYzfDD9clEgyBjyow+5QP0/W4Ds1Q7yZ9Bk5RRCW31r2uYMAg1wMIpygyQtWuoggiUoegbEf
+JTjPAeNq2Oln92fLOCV/wk7WYP4PJR0a2SqLzzUlxv56YrzlE+yMVrRdlcE7905wxRPCKNKVhvZEwkW5
+kGtDW4Imdi1dcVlrCCK1XnXMuIwG05qfjbeOb4jSNbYj67sbSHGWi4plY2YGe97VoYoduHfZel0ttQxq
+ex4PmvZpMMJI48uszzsIfzg2rSCl1et68xC5JN6HL5/5ruXD0p0aVT6lEtoUGAfQhNC9Sr8U755r7fxG
+TpxoukTGL5SbzEHQCYLV71YZaKjdr7nBsbS2hxDSFY3MDh1/f2Gbm5e3mMolc5zsmsO+jr+9hCcUvO6+
+XAzhENKnjlgiayg4ORfwk9HkXiJRhWVJULkXbRigDxcSWRtiszzChubJeiIsOnLE5FtHrZifqGg4E4QI
+3JPcwHAl7lrbWZJuV7LAZ5g2EGMTNcyqzCCWTRkPZWhmTqaumGMG3OBrPFxT+xyenzL41mhQAgBAY1BM
+3pFw4m4ttljDrdVQamj6bdgl/6SmqeZGXLA+nb8e4BRv/F3JFOFCQyurowA=`)
+
+	rawTar = func() []byte {
+		content, err := io.ReadAll(bzip2.NewReader(bytes.NewReader(tarBZ2)))
+		if err != nil {
+			panic(err)
+		}
+		return content
+	}()
+)
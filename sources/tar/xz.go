@@ -0,0 +1,868 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package tar
+
+import "io"
+import "hash"
+import "bytes"
+import "errors"
+import "hash/crc32"
+import "hash/crc64"
+import "crypto/sha256"
+import "encoding/binary"
+
+// There is no xz decompressor in the standard library, so this is a minimal one. It handles everything the xz tool
+// produces with its default settings: any number of concatenated streams, each containing any number of blocks using
+// the LZMA2 filter. Other filters (BCJ, delta, etc) are not supported.
+
+var errXZCorrupt = errors.New("tar: corrupt xz stream")
+var errXZFilter = errors.New("tar: unsupported xz filter, only LZMA2 is supported")
+
+var xzMagic = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+
+var crc64Table = crc64.MakeTable(crc64.ECMA)
+
+// xzReader decompresses an xz stream.
+type xzReader struct {
+	r     *byteCounter
+	flags []byte // The stream flags of the current stream.
+	out   []byte // Decompressed data that has not been read yet.
+	err   error
+	
+	// The current block, dec is nil between blocks.
+	dec     *lzma2
+	check   hash.Hash
+	start   int64 // Position of the start of the block header.
+	hsize   int64
+	csize   int64 // The sizes from the block header, -1 if not given.
+	usize   int64
+	written int64
+	
+	records [][2]int64 // Unpadded and uncompressed size of every block in the current stream.
+}
+
+func newXZReader(r io.Reader) (*xzReader, error) {
+	xr := &xzReader{r: &byteCounter{r: r}}
+	if err := xr.streamHeader(); err != nil {
+		return nil, err
+	}
+	return xr, nil
+}
+
+func (xr *xzReader) Read(b []byte) (int, error) {
+	for len(xr.out) == 0 && xr.err == nil {
+		xr.err = xr.step()
+	}
+	n := copy(b, xr.out)
+	xr.out = xr.out[n:]
+	if n > 0 {
+		return n, nil
+	}
+	return 0, xr.err
+}
+
+// step decompresses one LZMA2 chunk, or moves to the next block or stream.
+func (xr *xzReader) step() error {
+	if xr.dec == nil {
+		return xr.blockHeader()
+	}
+	
+	out := xr.out[:0]
+	done, err := xr.dec.chunk(&out)
+	if err != nil {
+		return err
+	}
+	xr.out = out
+	xr.written += int64(len(out))
+	if xr.check != nil {
+		xr.check.Write(out)
+	}
+	if done {
+		return xr.endBlock()
+	}
+	return nil
+}
+
+func (xr *xzReader) streamHeader() error {
+	hdr := make([]byte, 12)
+	if _, err := io.ReadFull(xr.r, hdr); err != nil {
+		return unexpected(err)
+	}
+	if !bytes.Equal(hdr[:6], xzMagic) || hdr[6] != 0 || hdr[7] > 0x0f {
+		return errXZCorrupt
+	}
+	if crc32.ChecksumIEEE(hdr[6:8]) != binary.LittleEndian.Uint32(hdr[8:]) {
+		return errXZCorrupt
+	}
+	xr.flags = append([]byte(nil), hdr[6:8]...)
+	xr.records = nil
+	return nil
+}
+
+func (xr *xzReader) blockHeader() error {
+	xr.start = xr.r.n
+	size, err := xr.r.ReadByte()
+	if err != nil {
+		return unexpected(err)
+	}
+	if size == 0 {
+		return xr.index()
+	}
+	
+	hdr := make([]byte, (int(size)+1)*4)
+	hdr[0] = size
+	if _, err := io.ReadFull(xr.r, hdr[1:]); err != nil {
+		return unexpected(err)
+	}
+	if crc32.ChecksumIEEE(hdr[:len(hdr)-4]) != binary.LittleEndian.Uint32(hdr[len(hdr)-4:]) {
+		return errXZCorrupt
+	}
+	
+	flags := hdr[1]
+	if flags&0x3c != 0 {
+		return errXZCorrupt
+	}
+	r := bytes.NewReader(hdr[2 : len(hdr)-4])
+	xr.csize, xr.usize = -1, -1
+	if flags&0x40 != 0 {
+		if xr.csize, err = readVLI(r); err != nil {
+			return err
+		}
+	}
+	if flags&0x80 != 0 {
+		if xr.usize, err = readVLI(r); err != nil {
+			return err
+		}
+	}
+	
+	if flags&0x03 != 0 {
+		// More than one filter.
+		return errXZFilter
+	}
+	id, err := readVLI(r)
+	if err != nil {
+		return err
+	}
+	psize, err := readVLI(r)
+	if err != nil {
+		return err
+	}
+	if id != 0x21 || psize != 1 {
+		return errXZFilter
+	}
+	props, err := r.ReadByte()
+	if err != nil || props > 40 {
+		return errXZCorrupt
+	}
+	for r.Len() > 0 {
+		if b, _ := r.ReadByte(); b != 0 {
+			return errXZCorrupt
+		}
+	}
+	
+	dict := uint32(0xffffffff)
+	if props < 40 {
+		dict = (2 | uint32(props)&1) << (props/2 + 11)
+	}
+	xr.dec = newLZMA2(xr.r, dict)
+	xr.hsize = int64(len(hdr))
+	xr.written = 0
+	switch xr.flags[1] {
+	case 0x00:
+		xr.check = nil
+	case 0x01:
+		xr.check = crc32.NewIEEE()
+	case 0x04:
+		xr.check = crc64.New(crc64Table)
+	case 0x0a:
+		xr.check = sha256.New()
+	default:
+		// Unknown checks are skipped.
+		xr.check = nil
+	}
+	return nil
+}
+
+func (xr *xzReader) endBlock() error {
+	xr.dec = nil
+	
+	csize := xr.r.n - xr.start - xr.hsize
+	if xr.csize != -1 && xr.csize != csize || xr.usize != -1 && xr.usize != xr.written {
+		return errXZCorrupt
+	}
+	for i := csize; i%4 != 0; i++ {
+		if b, err := xr.r.ReadByte(); err != nil || b != 0 {
+			return errXZCorrupt
+		}
+	}
+	
+	sum := make([]byte, checkSize(xr.flags[1]))
+	if _, err := io.ReadFull(xr.r, sum); err != nil {
+		return unexpected(err)
+	}
+	if xr.check != nil {
+		want := xr.check.Sum(nil)
+		if xr.flags[1] == 0x01 {
+			// The CRC32 is stored little endian, but hash/crc32 returns it big endian.
+			want = binary.LittleEndian.AppendUint32(nil, binary.BigEndian.Uint32(want))
+		}
+		if xr.flags[1] == 0x04 {
+			want = binary.LittleEndian.AppendUint64(nil, binary.BigEndian.Uint64(want))
+		}
+		if !bytes.Equal(sum, want) {
+			return errXZCorrupt
+		}
+	}
+	
+	xr.records = append(xr.records, [2]int64{xr.hsize + csize + int64(len(sum)), xr.written})
+	return nil
+}
+
+// index reads the index and stream footer, then the next stream header if there is one. The index indicator has
+// already been read.
+func (xr *xzReader) index() error {
+	crc := crc32.NewIEEE()
+	r := &byteCounter{r: io.TeeReader(xr.r, crc), n: 1}
+	crc.Write([]byte{0})
+	
+	count, err := readVLI(r)
+	if err != nil {
+		return err
+	}
+	if count != int64(len(xr.records)) {
+		return errXZCorrupt
+	}
+	for _, rec := range xr.records {
+		unpadded, err := readVLI(r)
+		if err != nil {
+			return err
+		}
+		uncompressed, err := readVLI(r)
+		if err != nil {
+			return err
+		}
+		if unpadded != rec[0] || uncompressed != rec[1] {
+			return errXZCorrupt
+		}
+	}
+	for r.n%4 != 0 {
+		if b, err := r.ReadByte(); err != nil || b != 0 {
+			return errXZCorrupt
+		}
+	}
+	isize := r.n
+	
+	sum := make([]byte, 4)
+	if _, err := io.ReadFull(xr.r, sum); err != nil {
+		return unexpected(err)
+	}
+	if crc.Sum32() != binary.LittleEndian.Uint32(sum) {
+		return errXZCorrupt
+	}
+	
+	footer := make([]byte, 12)
+	if _, err := io.ReadFull(xr.r, footer); err != nil {
+		return unexpected(err)
+	}
+	if crc32.ChecksumIEEE(footer[4:10]) != binary.LittleEndian.Uint32(footer) {
+		return errXZCorrupt
+	}
+	if (int64(binary.LittleEndian.Uint32(footer[4:]))+1)*4 != isize+4 {
+		return errXZCorrupt
+	}
+	if !bytes.Equal(footer[8:10], xr.flags) || footer[10] != 'Y' || footer[11] != 'Z' {
+		return errXZCorrupt
+	}
+	
+	// Streams may be followed by padding (in multiples of four bytes) and more streams.
+	for {
+		pad := make([]byte, 4)
+		n, err := io.ReadFull(xr.r, pad)
+		if n == 0 && err == io.EOF {
+			return io.EOF
+		}
+		if err != nil {
+			return errXZCorrupt
+		}
+		if bytes.Equal(pad, []byte{0, 0, 0, 0}) {
+			continue
+		}
+		if !bytes.Equal(pad, xzMagic[:4]) {
+			return errXZCorrupt
+		}
+		xr.r = &byteCounter{r: io.MultiReader(bytes.NewReader(pad), xr.r.r)}
+		return xr.streamHeader()
+	}
+}
+
+func checkSize(check byte) int {
+	switch {
+	case check == 0:
+		return 0
+	case check <= 0x03:
+		return 4
+	case check <= 0x06:
+		return 8
+	case check <= 0x09:
+		return 16
+	case check <= 0x0c:
+		return 32
+	}
+	return 64
+}
+
+// readVLI reads a variable length integer as used by xz.
+func readVLI(r io.ByteReader) (int64, error) {
+	var v int64
+	for i := 0; i < 9; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, unexpected(err)
+		}
+		v |= int64(b&0x7f) << (7 * i)
+		if b&0x80 == 0 {
+			if b == 0 && i != 0 {
+				return 0, errXZCorrupt
+			}
+			return v, nil
+		}
+	}
+	return 0, errXZCorrupt
+}
+
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// byteCounter is an io.ByteReader that keeps track of how many bytes have been read.
+type byteCounter struct {
+	r   io.Reader
+	n   int64
+	buf [1]byte
+}
+
+func (c *byteCounter) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *byteCounter) ReadByte() (byte, error) {
+	if _, err := io.ReadFull(c, c.buf[:]); err != nil {
+		return 0, err
+	}
+	return c.buf[0], nil
+}
+
+// lzma2 decodes the LZMA2 data in a single xz block.
+type lzma2 struct {
+	r   *byteCounter
+	w   window
+	dec lzmaDecoder
+	buf []byte
+	
+	needDictReset bool
+	needProps     bool
+}
+
+func newLZMA2(r *byteCounter, dict uint32) *lzma2 {
+	if dict < 4096 {
+		dict = 4096
+	}
+	return &lzma2{
+		r: r,
+		w: window{size: int64(dict)},
+		needDictReset: true,
+		needProps: true,
+	}
+}
+
+// chunk decodes the next chunk, appending the data to out. Returns true at the end of the data.
+func (d *lzma2) chunk(out *[]byte) (bool, error) {
+	control, err := d.r.ReadByte()
+	if err != nil {
+		return false, unexpected(err)
+	}
+	if control == 0x00 {
+		return true, nil
+	}
+	
+	if control >= 0xe0 || control == 0x01 {
+		d.needProps = true
+		d.needDictReset = false
+		d.w.reset()
+	} else if d.needDictReset {
+		return false, errXZCorrupt
+	}
+	d.w.out = out
+	
+	if control < 0x80 {
+		// Uncompressed chunk.
+		if control > 0x02 {
+			return false, errXZCorrupt
+		}
+		size, err := d.readSize()
+		if err != nil {
+			return false, err
+		}
+		for i := 0; i < size; i++ {
+			b, err := d.r.ReadByte()
+			if err != nil {
+				return false, unexpected(err)
+			}
+			d.w.put(b)
+		}
+		return false, nil
+	}
+	
+	usize, err := d.readSize()
+	if err != nil {
+		return false, err
+	}
+	usize += int(control&0x1f) << 16
+	csize, err := d.readSize()
+	if err != nil {
+		return false, err
+	}
+	
+	switch {
+	case control >= 0xc0:
+		props, err := d.r.ReadByte()
+		if err != nil {
+			return false, unexpected(err)
+		}
+		if err := d.dec.setProps(props); err != nil {
+			return false, err
+		}
+		d.needProps = false
+		d.dec.reset()
+	case d.needProps:
+		return false, errXZCorrupt
+	case control >= 0xa0:
+		d.dec.reset()
+	}
+	
+	if cap(d.buf) < csize {
+		d.buf = make([]byte, csize)
+	}
+	d.buf = d.buf[:csize]
+	if _, err := io.ReadFull(d.r, d.buf); err != nil {
+		return false, unexpected(err)
+	}
+	rc, err := newRangeDecoder(d.buf)
+	if err != nil {
+		return false, err
+	}
+	if err := d.dec.decode(rc, &d.w, usize); err != nil {
+		return false, err
+	}
+	if !rc.finished() {
+		return false, errXZCorrupt
+	}
+	return false, nil
+}
+
+// readSize reads a 16 bit big endian size (minus one).
+func (d *lzma2) readSize() (int, error) {
+	var b [2]byte
+	if _, err := io.ReadFull(d.r, b[:]); err != nil {
+		return 0, unexpected(err)
+	}
+	return int(binary.BigEndian.Uint16(b[:])) + 1, nil
+}
+
+// window is the LZMA dictionary, a circular buffer that is only allocated as it is needed.
+type window struct {
+	buf   []byte
+	pos   int   // Where the next byte goes, once the buffer is full.
+	size  int64 // The dictionary size.
+	total int64 // Bytes written since the last reset.
+	
+	out *[]byte // Every byte written is also appended here.
+}
+
+func (w *window) reset() {
+	w.buf = w.buf[:0]
+	w.pos = 0
+	w.total = 0
+}
+
+func (w *window) put(b byte) {
+	if int64(len(w.buf)) < w.size {
+		w.buf = append(w.buf, b)
+	} else {
+		w.buf[w.pos] = b
+		w.pos++
+		if int64(w.pos) == w.size {
+			w.pos = 0
+		}
+	}
+	w.total++
+	*w.out = append(*w.out, b)
+}
+
+// get returns the byte dist bytes back (1 is the last byte written).
+func (w *window) get(dist uint32) byte {
+	i := len(w.buf) - int(dist)
+	if int64(len(w.buf)) == w.size {
+		i = w.pos - int(dist)
+	}
+	if i < 0 {
+		i += len(w.buf)
+	}
+	return w.buf[i]
+}
+
+// valid returns true if there are at least dist bytes in the dictionary.
+func (w *window) valid(dist uint32) bool {
+	return int64(dist) <= w.total && int64(dist) <= w.size
+}
+
+// rangeDecoder is the LZMA range decoder, reading from a single LZMA2 chunk.
+type rangeDecoder struct {
+	in   []byte
+	rng  uint32
+	code uint32
+	bad  bool // Set if the decoder tried to read past the end of the chunk.
+}
+
+func newRangeDecoder(in []byte) (*rangeDecoder, error) {
+	if len(in) < 5 || in[0] != 0 {
+		return nil, errXZCorrupt
+	}
+	return &rangeDecoder{
+		in: in[5:],
+		rng: 0xffffffff,
+		code: binary.BigEndian.Uint32(in[1:5]),
+	}, nil
+}
+
+func (rc *rangeDecoder) normalize() {
+	if rc.rng < 1<<24 {
+		rc.rng <<= 8
+		if len(rc.in) == 0 {
+			rc.bad = true
+			rc.code <<= 8
+			return
+		}
+		rc.code = rc.code<<8 | uint32(rc.in[0])
+		rc.in = rc.in[1:]
+	}
+}
+
+func (rc *rangeDecoder) bit(p *uint16) uint32 {
+	bound := (rc.rng >> 11) * uint32(*p)
+	var bit uint32
+	if rc.code < bound {
+		rc.rng = bound
+		*p += (2048 - *p) >> 5
+	} else {
+		rc.rng -= bound
+		rc.code -= bound
+		*p -= *p >> 5
+		bit = 1
+	}
+	rc.normalize()
+	return bit
+}
+
+func (rc *rangeDecoder) direct(n uint32) uint32 {
+	var v uint32
+	for ; n > 0; n-- {
+		rc.rng >>= 1
+		rc.code -= rc.rng
+		t := 0 - (rc.code >> 31)
+		rc.code += rc.rng & t
+		rc.normalize()
+		v = v<<1 + t + 1
+	}
+	return v
+}
+
+// tree decodes a bits bit number using a bit tree.
+func (rc *rangeDecoder) tree(probs []uint16, bits uint32) uint32 {
+	m := uint32(1)
+	for i := uint32(0); i < bits; i++ {
+		m = m<<1 | rc.bit(&probs[m])
+	}
+	return m - 1<<bits
+}
+
+// reverse is like tree, except the bits are in the reverse order.
+func (rc *rangeDecoder) reverse(probs []uint16, bits uint32) uint32 {
+	m, v := uint32(1), uint32(0)
+	for i := uint32(0); i < bits; i++ {
+		b := rc.bit(&probs[m])
+		m = m<<1 | b
+		v |= b << i
+	}
+	return v
+}
+
+// finished returns true if the chunk was decoded exactly.
+func (rc *rangeDecoder) finished() bool {
+	return !rc.bad && len(rc.in) == 0 && rc.code == 0
+}
+
+const (
+	lzmaStates      = 12
+	lzmaPosBitsMax  = 4
+	lzmaEndPosModel = 14
+	lzmaFullDists   = 128
+	lzmaAlignBits   = 4
+	lzmaMinMatch    = 2
+)
+
+type lenDecoder struct {
+	choice  uint16
+	choice2 uint16
+	low     [1 << lzmaPosBitsMax][1 << 3]uint16
+	mid     [1 << lzmaPosBitsMax][1 << 3]uint16
+	high    [1 << 8]uint16
+}
+
+func (ld *lenDecoder) reset() {
+	ld.choice, ld.choice2 = 1024, 1024
+	for i := range ld.low {
+		fill(ld.low[i][:])
+		fill(ld.mid[i][:])
+	}
+	fill(ld.high[:])
+}
+
+func (ld *lenDecoder) decode(rc *rangeDecoder, posState uint32) uint32 {
+	if rc.bit(&ld.choice) == 0 {
+		return rc.tree(ld.low[posState][:], 3)
+	}
+	if rc.bit(&ld.choice2) == 0 {
+		return 8 + rc.tree(ld.mid[posState][:], 3)
+	}
+	return 16 + rc.tree(ld.high[:], 8)
+}
+
+// lzmaDecoder holds the LZMA state that is kept between LZMA2 chunks.
+type lzmaDecoder struct {
+	lc, lp, pb uint32
+	
+	state uint32
+	reps  [4]uint32
+	
+	literal     []uint16
+	isMatch     [lzmaStates << lzmaPosBitsMax]uint16
+	isRep       [lzmaStates]uint16
+	isRepG0     [lzmaStates]uint16
+	isRepG1     [lzmaStates]uint16
+	isRepG2     [lzmaStates]uint16
+	isRep0Long  [lzmaStates << lzmaPosBitsMax]uint16
+	posSlot     [4][1 << 6]uint16
+	posDecoders [1 + lzmaFullDists - lzmaEndPosModel]uint16
+	align       [1 << lzmaAlignBits]uint16
+	lens        lenDecoder
+	repLens     lenDecoder
+}
+
+func (d *lzmaDecoder) setProps(props byte) error {
+	if props >= 9*5*5 {
+		return errXZCorrupt
+	}
+	d.lc = uint32(props % 9)
+	d.lp = uint32(props / 9 % 5)
+	d.pb = uint32(props / 45)
+	if d.lc+d.lp > 4 {
+		return errXZCorrupt
+	}
+	return nil
+}
+
+func (d *lzmaDecoder) reset() {
+	d.state = 0
+	d.reps = [4]uint32{}
+	
+	n := 0x300 << (d.lc + d.lp)
+	if cap(d.literal) < n {
+		d.literal = make([]uint16, n)
+	}
+	d.literal = d.literal[:n]
+	fill(d.literal)
+	fill(d.isMatch[:])
+	fill(d.isRep[:])
+	fill(d.isRepG0[:])
+	fill(d.isRepG1[:])
+	fill(d.isRepG2[:])
+	fill(d.isRep0Long[:])
+	for i := range d.posSlot {
+		fill(d.posSlot[i][:])
+	}
+	fill(d.posDecoders[:])
+	fill(d.align[:])
+	d.lens.reset()
+	d.repLens.reset()
+}
+
+func fill(probs []uint16) {
+	for i := range probs {
+		probs[i] = 1024
+	}
+}
+
+// decode decodes exactly n bytes into w.
+func (d *lzmaDecoder) decode(rc *rangeDecoder, w *window, n int) error {
+	pbMask := uint32(1)<<d.pb - 1
+	for n > 0 {
+		if rc.bad {
+			return errXZCorrupt
+		}
+		posState := uint32(w.total) & pbMask
+		
+		if rc.bit(&d.isMatch[d.state<<lzmaPosBitsMax+posState]) == 0 {
+			d.literalByte(rc, w)
+			n--
+			continue
+		}
+		
+		var length uint32
+		if rc.bit(&d.isRep[d.state]) != 0 {
+			if w.total == 0 {
+				return errXZCorrupt
+			}
+			if rc.bit(&d.isRepG0[d.state]) == 0 {
+				if rc.bit(&d.isRep0Long[d.state<<lzmaPosBitsMax+posState]) == 0 {
+					// Short rep, a single byte.
+					if d.state < 7 {
+						d.state = 9
+					} else {
+						d.state = 11
+					}
+					if !w.valid(d.reps[0] + 1) {
+						return errXZCorrupt
+					}
+					w.put(w.get(d.reps[0] + 1))
+					n--
+					continue
+				}
+			} else {
+				var dist uint32
+				if rc.bit(&d.isRepG1[d.state]) == 0 {
+					dist = d.reps[1]
+				} else {
+					if rc.bit(&d.isRepG2[d.state]) == 0 {
+						dist = d.reps[2]
+					} else {
+						dist = d.reps[3]
+						d.reps[3] = d.reps[2]
+					}
+					d.reps[2] = d.reps[1]
+				}
+				d.reps[1] = d.reps[0]
+				d.reps[0] = dist
+			}
+			length = d.repLens.decode(rc, posState)
+			if d.state < 7 {
+				d.state = 8
+			} else {
+				d.state = 11
+			}
+		} else {
+			d.reps[3], d.reps[2], d.reps[1] = d.reps[2], d.reps[1], d.reps[0]
+			length = d.lens.decode(rc, posState)
+			if d.state < 7 {
+				d.state = 7
+			} else {
+				d.state = 10
+			}
+			d.reps[0] = d.distance(rc, length)
+			if d.reps[0] == 0xffffffff {
+				// End marker, not allowed in LZMA2.
+				return errXZCorrupt
+			}
+		}
+		
+		length += lzmaMinMatch
+		if int(length) > n || !w.valid(d.reps[0]+1) {
+			return errXZCorrupt
+		}
+		for i := uint32(0); i < length; i++ {
+			w.put(w.get(d.reps[0] + 1))
+		}
+		n -= int(length)
+	}
+	return nil
+}
+
+func (d *lzmaDecoder) literalByte(rc *rangeDecoder, w *window) {
+	prev := uint32(0)
+	if w.total > 0 {
+		prev = uint32(w.get(1))
+	}
+	lit := (uint32(w.total)&(1<<d.lp-1))<<d.lc + prev>>(8-d.lc)
+	probs := d.literal[0x300*lit:]
+	
+	symbol := uint32(1)
+	if d.state >= 7 && w.valid(d.reps[0]+1) {
+		match := uint32(w.get(d.reps[0] + 1))
+		for symbol < 0x100 {
+			matchBit := match >> 7 & 1
+			match <<= 1
+			bit := rc.bit(&probs[(1+matchBit)<<8+symbol])
+			symbol = symbol<<1 | bit
+			if matchBit != bit {
+				break
+			}
+		}
+	}
+	for symbol < 0x100 {
+		symbol = symbol<<1 | rc.bit(&probs[symbol])
+	}
+	w.put(byte(symbol))
+	
+	switch {
+	case d.state < 4:
+		d.state = 0
+	case d.state < 10:
+		d.state -= 3
+	default:
+		d.state -= 6
+	}
+}
+
+func (d *lzmaDecoder) distance(rc *rangeDecoder, length uint32) uint32 {
+	lenState := length
+	if lenState > 3 {
+		lenState = 3
+	}
+	slot := rc.tree(d.posSlot[lenState][:], 6)
+	if slot < 4 {
+		return slot
+	}
+	
+	direct := slot>>1 - 1
+	dist := (2 | slot&1) << direct
+	if slot < lzmaEndPosModel {
+		return dist + rc.reverse(d.posDecoders[dist-slot:], direct)
+	}
+	dist += rc.direct(direct-lzmaAlignBits) << lzmaAlignBits
+	return dist + rc.reverse(d.align[:], lzmaAlignBits)
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+
+package tar_test
+
+import (
+	"bytes"
+	"testing"
+	
+	axistar "github.com/milochristiansen/axis2/sources/tar"
+)
+
+func TestXZ(t *testing.T) {
+	tests := map[string][]byte{
+		"blocks": tarXZ,
+		"streams": tarXZStreams,
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			ds, err := axistar.NewRawDir(content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			check(t, ds)
+			
+			ds, err = axistar.NewStreamDir(bytes.NewReader(content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			check(t, ds)
+		})
+	}
+}
+
+func TestXZCorrupt(t *testing.T) {
+	for name, content := range map[string][]byte{"blocks": tarXZ, "streams": tarXZStreams} {
+		// Every byte is covered by a check of some kind.
+		for i := 0; i < len(content); i += 7 {
+			c := append([]byte(nil), content...)
+			c[i] ^= 0x55
+			if _, err := axistar.NewRawDir(c); err == nil {
+				t.Errorf("%v: corrupting byte %v was not detected", name, i)
+			}
+		}
+		
+		for i := 1; i < len(content); i += 13 {
+			if _, err := axistar.NewRawDir(content[:i]); err == nil {
+				t.Errorf("%v: stream truncated to %v bytes was not detected", name, i)
+			}
+		}
+	}
+	
+	// Trailing garbage is not padding.
+	if _, err := axistar.NewRawDir(append(append([]byte(nil), tarXZ...), 0, 0, 0, 1)); err == nil {
+		t.Error("trailing garbage was not detected")
+	}
+}
+
+func FuzzXZ(f *testing.F) {
+	f.Add(tarXZ)
+	f.Add(tarXZStreams)
+	f.Fuzz(func(t *testing.T, content []byte) {
+		// Anything is fine, as long as there is no panic (or runaway memory use).
+		if ds, err := axistar.NewRawDir(content); err == nil {
+			ds.List()
+		}
+	})
+}
+
+// tarXZ is the test archive (see rawTar) compressed with xz using SHA-256 checks, 1KB blocks (so random.bin needs
+// uncompressed chunks), and non-default LZMA settings (lc=1, lp=2, pb=0). tarXZStreams is the archive split into
+// three parts that were compressed separately (with CRC32, CRC64, and no check) and then joined, with stream padding
+// after the first part.
+var (
+	tarXZ = mustDecode(`
+/Td6WFoAAArh+wyhA8BSgAghARYAAAAAz/aF6OAD/wBKEwAXDBoes+F/nhRFNlRQb2ohgJHTyef0ZTUw
+FAU1/UaFjFPRYChj9LQDBNktUsuiTgwwr4bbGQ6Wk9XAmEPXq3CyfkFVTw7d8QbbAAAAAAUrRX2YGxsH
+hXTKMujS0JwNbqw0Lh8f6NBmJ3dVHexeA8BUgAghARYAAAAAiIab5eAD/wBMEwAXDCdJdhPnedDlA2JQ
+tp65XX51Duq7GmIcosuOFfdfBwuz1SpYJL7hLepApozyvyWo3xOGKK4SrV58bEbNioVKg9+E/aB7dT1y
+Zz4AAGqZOIApknlRESPAqeijGKaGjdrUjKfig4GWkHsvgXmUA8BSgAghARYAAAAAz/aF6OAD/wBKEwAX
+DCdsac/7RWrGYT1E4HuU6T5fmPVOU50oHuGG7mNiwo5TlxV06aUdmnzPrLnHxHYscEd8xteTlToH52kE
+soTDCeXKp5376eHP/QAAADUpyIW2TlqNUxeE4JJ023xvASMNhprx9r9qEv8xsInWA8CuAYAIIQEWAAAA
+Eyo+6eAD/wCmEwAXDCjPHu0Q+26K3be6N7VKksxog32f21m0SLlnUp/gbJVyQ+c4HugcN7QWbYLghMdg
+Yjk7GS0MhXVZYjQ3QkeCS83kMAVqE9OGkicnkE9r0+e7FDn547Bjk2jlbAWECaFNetN0jsQS+niTj6vI
+ei9TSfWViJzetY4Iw4TzOfOtyD0he/O9k4OVguOZp0X12n10PbQKeSpDl+xkkLzS4Glbj5vPRD71AAAA
+rvNzBJJiu56bSigmlgs1NajR7HsgyCktTun32jshtcADwKIBgAghARYAAACdygLz4AP/AJoTADcaCmUB
+1TnwMjcPJSzBSyhZREKMwEwuDbkM6jFlWIHh5uKsOXOM/sjCKiiHdjQ6W+Z0+0li86Fp20qMFuEyWDz8
+cbopC4MV6tnxYtywEQNNmEDqjFRFhprqlKnNCDOTKNeoPLHFGNiSpQtXiZFoQWdxZsIynziI5t6TS/qJ
+2shrmq/dl/jMq4TrAy1rfUoN/mokOAkNN5psZ34AAACzUlqv/Tn7Gl0bKEhJfiS8a6oyljQ/HSv74oWD
+GPyoNwPAmwGACCEBFgAAAG9j5GfgA/8AkxMABRviZmYUriwRSphbTUxL6inJ1sQ02CzmXo/aHzSSEAMA
+6S+uQyV5NQRNyGBjajZmFaFyrFegrcCkGbNkkzkCIGnZIfQIpTxDvVl0z4nm6sAt5RCZ41gAFTZEByjU
+Cj1oanhj63Q8uWL7/nsWYl96vWZG/7VqtIhhaXAlzl3pNnpH1fv++i8xTztkh7GE6+LgT9UvAADbfNz/
+qMUeWNat/xQSKe2x84+RcefNeaXAYStQNn2LqAPAZ4AIIQEWAAAAALO/X2bgA/8AXxMAHI3pZmAKX1pi
+B5fUs7HYbaxYJt66QPGXirrcxnkdG9nP19drQhPCS0kbW8fMrNqFnCMs3eQhEC4gNYPp8vBmvo+zl2qG
+xy3u2gGR9E/tNQiE3eYO/LUQfAD967ifGAAAAIXLPvHYXMnLgOIdI8UYobljT9l8RHkEOAyk015ltpZX
+A8DUBIAIIQEWAAAADXrCQuAD/wJMEwAXDCmgs9/LAFYRvBV1SPdbkYxytQFi8dJPAGkHBON48VfTCZUJ
+Czk1/MziUJTYnoHjEnG0xVIPHtWYeMu6HbbkyvoGnbNq4sCNn6F3TC+nDsLrl7zKMwDn9IiRh6M6xm9p
+vWfJF2l8HMzBKY7NBz6JmLdyKwo8Gw4sf1hQasUVnOpNkrmrt9s2eDAozPmZwNbiFhLBGLbMyG6QbcwL
+OQ4H5gaTuNUoBSj2vUr9apNmy7jTPSVreWEGTf4gXdYf2AGWhqikEOLCufG+tz1lJsn6m4OQv8szYOkA
+UbRXH+akdGW28FEZSkbi+3lB3IcCGzUeFB6V5vXhgwHyyCuTDPDCiL+i5SIKpiOJe7dh7j3gP3q3Q5LP
+qb4/z9wlTdz+ck2quLJo+eL6IMiOzL5LFP62RPYd1NA4We+9qUDti3y4R+iTJyvSvvbPevOACTnSZWRb
+l04M1/KIEHGY/up6QTM3RNNh6bK4hgDnttb+IZ0w3y2Mhx6BVaxBcnY1OZFJVaioQjXbQPluisOxoS/V
+IQoelSsA+JnQ2n7Mz3Zs5aJpy4CDKZspVBPALsUTNDK/Jpqfg2Sly8S9PHvzrYLQBRXtZugR5aCO4GjB
+Kry4NI1z9EhFsNk3IzzaNv9nM38dGFCAbfq2C60eQdwmQ8tqKzOxO93OdxsPdY7pumUVPOi/J/2i/YMn
+3of5iPGYC5Du88lZNcaBTVdtivJ4XYVAMEG+nQR5o0XCEB1ocafJNU4tWDUCtoX7vrkKyVLbVvHa+SnF
+6IWlcRkYvuV0SWAAJXWu6Oopnr/wFSVLmAj5mreFuAFHy+RZO9yjBadsHX4DwIQIgAghARYAAABRD+Id
+AQP/00sDnasDF2kd0+LKCjA9yfyWaykdcyquPSi+2Bpv6fZgzviK6NFLjEC2elAZNaZRCgYCyfvsS7mY
+UXNkUGYQEOlR+Jn4dBxAN8iex/rkit6weKlbQi6KNU4yP1wU0UcW+8ByF6aTpFbwOmP3TgpTL1HK2JTk
+600+VRmLnJTOmBc+OAXOPmYSRI3eEroTBaICSsDKW3543NsnGYDHy1MTgvOqLC3GJvwk0t1RThu1g9Xr
+mksg5DQki+m4CMdQ0uefzazojdfxv/ywNC1MbokoDLbcqj9AxxCu9nLOboxAinDZiXQCZdZWK0J8Bsul
+7mr5kgQPsVqUI5cgI0L71EZlkGYsnBY7fAEth1GA5KbrcO6vo7s5PVB+r3r0ObZpVo+c6Lrqp0b4pTgM
+6xLDgqXgXiiCxMriNE9MsUzZjV8qs7O8dpgV2x/lm/WDkmAtJ0BtN/GRuMHIDX6uZLejWWKD2CqLuv4K
+hvsXzkGgGUS86RX1+SP4xp3X96ivsxRx2ew9+Nlh8M3nbmUq6FNwIJ/ofPU2Hm6ZiGjoHqlLRz9gv48B
+9TCHcJQFB6D5mz7VQjQsSCWKM0VPlcFA1a5yytzP2vkri1t9a9sfxDWS4WI0SM8b584GHpG/A4tL96zC
+ufmmIhOAX5LOT2+ArVvCh1IAH3G3c1lOimZWyLuukn4cpepgYTSOAP5Hopm44b3UuoIy/Ox2mdWEaO++
+tvz8TrMrc56rhzJchgCtY5Rt+GdW3J+V+buz5fe/EX78vj+j96ZKoQVouKEnosfvZchF2C3EEtDGmgJZ
+6UPMtWnfr4tNJnbVQnwrd4ILRYIZvpdsEVoRqHEFKoG18imwF2aisEaaTTWHNTziVUQRE7LU6YWoXneC
+jrwMK0ynvLb/0I5FW5y9O2SPZix7ykLdnFS3OEL2nLQ+2KkH2uben2dR7W7uwj/JRDASoLsq3vmUcZTp
+7rolm/JDdYYpI8cj5LdwXE/AZj0dtzS3rk4RGzplUn7tGfQvCw7PmAXjwDeuCH60h9C59uOccVep1kYe
+nLEsGDhmO35zYMAr+Ts80Uh2jJRjNnO3QlR/lxzoNv4UCwPMAdt6UeNi2ZRJ6zJmKOHTwqUmy+kHA2Ml
+4KqKDpBhQSEUdqbXTecDCYkPhtchCu5Gxx5uFzAHf6Mhvkev0dgxqXJjVKFE+EKkoj4+D5bvyZcsWW2a
+so+jhfgP51qMaYkztuGJbOupEbZEvpy4+MASQC35GCYP6zTabdoLDaMX6dCDeIBeGfxQCiCICHGqIOVl
+w7Xm4XIGvIZFF0DMUxVNCNxiDrtCULwhQsthzh3brU0YbNc+gI40VOxWgshk9OWVexohp9ByhvyPuNjV
+lLOFiQfl+gDzpluYgtdxdqLate9nqiqBeadV9ngs0nXxU3CZnu8pBgPA7QSACCEBFgAAAP/TJNbgA/8C
+ZRMAakFALmvh5eGm6GEAyR7vFaTqoWBEiZ+N9ad4YUIpnt8JgewrWdlLtJNTnSY0ydLhYcYY5SWYJrt9
+1AkjUJ0OwzQb5zak3feRxLAcbPKSTkJGfMYhRMunDOneW36ND/LfhClztpVOwt8VPT+shWuMBrEHLenO
+2an2AaqRWEMpsRMotPOsqFkwzK6ZEJwPEgZAiFQSI5Za3TTsIrz2V08UfrpTRxu7d5Bk86HOgqtWCe2T
+nESrstTBkjzLjTk0bHpkhVl7rnJ/qlsfpz9Lm1aLPz9f4NK48jERYoTz357M0Wz5h0lgEioB047ZasSI
+mt+lW5qYli9A5bID3v0HsbzDfLazRwF05idnCeUBs/HcuUzainYQR9jAxpDQo0rA4Ih0OieonRA7ZRJR
+0czUo0dl9G9VLGRi5JWLsADmldfru6Myd+/1DnCWWmPLWFKmtzHiLmFiVsaysbrEv4VK+oEQwHWDXLNa
++boBZBGYzfr6m9Zluw6evPCUxmW5hRes6+6HV4/Oyv+EKvOg0O/kqLnCy+YUU4dkUU+iWtrP3/hepYPf
+85R5PRubv0uiu8AUrDkVeplvX7mZx+RHouvOTWG4wdE+BjPdga+pp437jvGYU30ircbrfJcvwwUVgfco
+bP+kcMFZtbkesOrpe09nzsR/puVKYpjuTFJkOgwqDPMxdX+0gDJrm9AcpF/DpBdjrFIE3EOSZATf/5no
+yA4IuhQD1EGyXoLo7gH6PcN/1h6W9jajzpVCS/uZEpGOiJT0mzs7rhQv9aDc7sOIf3sdYCg3YgA2XC+z
+9sg7OpEPpmaFvdpfocPEyAAAAAC/QicWTd2B3zWs7snAsX8k4LwcvliCq1mGlRzrXUEkbAPAHIAIIQEW
+AAAAANzB9mjgA/8AFBMAAHB9/f+uj+qN8u4R5Il5fS7aoAAAFpdyNv3hSrmJkVHeDVZpTf6tVdlpZnpA
+9QvlDe6/Q/sDwBOACCEBFgAAAABRmv2Z4AP/AAsTAABwff3/rpAdX+IAAABfcL8YoIYAcBbpSLBK7TuC
+EDo2vqQXVbbN368QrOPG7wPAE4AIIQEWAAAAAFGa/ZngA/8ACxMAAHB9/f+ukB1f4gAAAF9wvxighgBw
+FulIsErtO4IQOja+pBdVts3frxCs48bvA8ATgAghARYAAAAAUZr9meAD/wALEwAAcH39/66QHV/iAAAA
+X3C/GKCGAHAW6UiwSu07ghA6Nr6kF1W2zd+vEKzjxu8DwBOACCEBFgAAAABRmv2Z4AP/AAsTAABwff3/
+rpAdX+IAAABfcL8YoIYAcBbpSLBK7TuCEDo2vqQXVbbN368QrOPG7wPAE4AIIQEWAAAAAFGa/ZngA/8A
+CxMAAHB9/f+ukB1f4gAAAF9wvxighgBwFulIsErtO4IQOja+pBdVts3frxCs48bvA8ATgAghARYAAAAA
+UZr9meAD/wALEwAAcH39/66QHV/iAAAAX3C/GKCGAHAW6UiwSu07ghA6Nr6kF1W2zd+vEKzjxu8DwBeA
+CCEBFgAAAAArOhaQ4AP/AA8TAABwff3/rpAY4Gz945wAAAAAXSIsfpReFuv7lV8eNKEu882LqXcW19lY
+MFxY/ZsesEQDwBOACCEBFgAAAABRmv2Z4AP/AAsTAABwff3/rpAdX+IAAABfcL8YoIYAcBbpSLBK7TuC
+EDo2vqQXVbbN368QrOPG7wPAE4AIIQEWAAAAAFGa/ZngA/8ACxMAAHB9/f+ukB1f4gAAAF9wvxighgBw
+FulIsErtO4IQOja+pBdVts3frxCs48bvABSCAYAIhAGACIIBgAjeAYAI0gGACMsBgAiXAYAIhAWACLQI
+gAidBYAITIAIQ4AIQ4AIQ4AIQ4AIQ4AIQ4AIR4AIQ4AIQ4AIAgA3rS3rCR8SAAAAAApZWg==`)
+	tarXZStreams = mustDecode(`
+/Td6WFoAAAFpIt42BMCwA9g2IQEWAAAAAAAAAMDNxr7gG1cBqF0AFwu8HH0BlcAdSj55FcLMJqNcjgoO
+WQ/x/2j7a70XxFI2Qvv2hJLmEF61h6xWFB/CwWZmvZsrIE5fGQ4dJYGbKCcZfVt6DD8vYO60gvHwY79E
+mxV9Gh4Xhobp1+mkSEdK0q02n5jTLVpZIpEP4zFin0JZ4M0ZvsJvQUSJupsALQ4LPAYoNfDfmzL0XsS6
+RzPhpXEG8XaWKfbditHNb5NLLfK2Mzb43cFRQMn5CeA/fuChx0oIp4iGk8Lf7PD7D7BboU1vBmMZF1ax
+wk+OqVvDxf31vwkBqgPzwqpMgEoELZIz29ZwJpb/V9db3pQ9szQ1yGy8H3Vx+G7gMfvgqJi/i7q/t+iY
+SdkPZQ1Mgtb6PrSKY9SoZ+1GccYnBb9j5RIfOhRWF5eEAth/StFQrMALxCVglk7shUzrzT7Nu9sp/nSy
+MvDWReVaKfCHPZXd5foJy0Ufqh1TzKlPBBlWWogRBcyKT+0OkFww8uP5Jl/A3qmleyfo40qDWQ0FzXwu
+w9+vNAvCuq7JtyfM3EH+Zur2F3I2k6sFQ4+Hb5Ym9jSrL68vf03TczgMswCCF3HoAAHIA9g2AACAOki6
+PjANiwIAAAAAAVlaAAAAAP03elhaAAAE5ta0RgTAwBHYNiEBFgAAAAAAAAAUbfKA4BtXCLhdAABvKHFu
+5S6L7m58mkrSVbIqR6d/j1CatgZJ0zfiE88JBLsPDYm730RYH8LsBOa8l/72HvtOdYiQoQFxkhJYoYMI
+Mpn34p1+5hGo1O42LhvMgc0OD5uaojAPgCg6+jHic3Sow/L4M3UY/c26rYjUqCmWLASj6sk2w5m/Ooji
+b7TnGW6uWzhGVs5MHHzXsaUdiYhL3sck1dLoUAWuDc/NYv5DAQab1JPYDdY9v7hIkfitvFqVtSOdUh9a
+bFe9KP8T5SKrnND5Mi74ZH8lMfPhDAwIzQ3Cf27vqRr3kbs/h9tlbR8sQdcWHB7Wfy1G0T8VU9jDoloj
+ae2IwEjs18r2U6rEIvm2FGupv48olejJpig/NCzQ7odpuy7T8RUKFF9zdNCHhOnrd37SJRAaVD+2irEQ
+36rAUfsF3zwzF0HhO/xSQVS+eSC52+QqSEb/w7X+QKK93agBmlOcYFrppfoy0PEhYZqVNR4Pk/Wv6g44
+btEgtrk7rVfaoxgGAo3CMV2NwwZAVGRkou+rAyNZi37qTxYH3VW2Ym8WlyVrw7CedWh8J7RdsR8gzE77
+NanYRlO2qn/sCdxWgiFeqySPcJybmfTA7XQ/XaSoNyBvqKkAN/x9e1IWIPKNF9XgG57V/dGLO6AG0jM+
+v4VlFbWTIqW2j+cjucrYTLufAc+NpGdR2YTnXMQZAf0ISQgH4AApkozNeQV6OToApmo3i2/YSw2Fw7uI
+D1xXJdjAbcieAqOYOp/bWeK8uBRJV2DoWnm7V9fu+mKkv+Z5tqLLrS8BQvIYtwOi6JmSuvDvtYsgcJcf
+RMphHUc1ao3SKLqnA1ieSyQcmTnCIJwv0WPQr3vcmX72hvFodCCKe1xzp4itA/A5Jvdo7TR8o4NakQNX
+Bg7Hcs8V+wvbdF3hPKge5Qb3x5hmSO20AEzbaKp7/YbmJcZYvMR8vQW5f9AbLkMfDspRVXwQEBJHAQwN
+4AFzwEBXaI7LBglo2e1vSDQqpSO7/ni8+i3Fp1j5sU0lUJkL+wl2mr41V+4MwHTIkSADw3Ivw3pjMkNk
+NcOIlqK7mtMOq1Yc2SwQ/d3CKPw9DFbXdIlmC7n4c1eRsFbamJ80UsyPfv82jU6Egi2V37OA9JMG/TPg
+aMjlwLxRl8TUJKdEj1J4tvVGgbKFPQf58JD1+WdiKFl3fTslleljPg5VqVXDxk9hM568EbtCVP6xr7nx
+7P4GDeSX1c7xZAHRG/7rrPR5yttHsFT/inrtbHqsXOYnrBOjTumSxtHjIOgMdN6BbHuhn8nJ/C6YhxFN
+UJlCWi0Os/rte3rNSIhg/oITrHhTG2opVa52YmXW7F84FjMnB9o7muDfyZXTVVnZXNLjAGhnto7bbm3b
+ecDVjQYZ4CZgwz5RqTMO1qKHf89PS+j8RoIqJ1g3gou45Ms73myeF2MqSElWge0jkyJ4K7XHunWl2w6n
+21P0Mi62JxgUMf7kpYwLQzhXKvlOnhoh3n0Kkqn95y42iyOidbwM3QNGufddYtsHa6k8Y+cHDB+RChm7
+HUYX8jGEZmFYrJ+Kv/mhGaydMQItMsYLjUHH5gHyR8UTTPsYqJxLb7oTEXM7H46PFJnG8WnseJGvxaQZ
+cDhxHCdgE8kEyx51hwM/1S38EHJc0kJ7C03VyskCi0QBWR9VU9JwyzmaLK0L8tUyIbueI2Y9yxSivyVO
+f4Rot1iHkZHOFxYF4Ea3s/JOTvedWFcUCjMcSRniqH6OOJZyVC3d70JpPbC4IcRWh83BSDK15Mp7CEZE
+LYxahdpKnKY/qcmGMgoFAla7627PzuMVpo1pKAD9tCNYwBri2YzueA0qHx1R5nHipfTXYE1KA1r+7+85
+QBZcBrFt6mKN+ZZUQEoP13l+jyh81qzu2ANetosL1BU0ns29sYUvjnsrlSRhw2cDyW9M6ltlikPIZ/Eh
+ReCezOwS/1XPmZLEScVuBq/G8HHPho1Wb1UGKdzfnXI8boezuwW0rZvumk597w9UNdVVZC3VzkCObkqm
+vPgQHM7aaPgoHVJTtROcjNwltoTW+0l8CwWfBd5ci4JiQu6iVcTXrolBdRqhfqZrWQlD54+pPLLS1bU+
+dPKyaEIbtnjZaBDW1VptysrlpFCIuHDdz7aWM9cokyhouAC6gnd2JBirKZzAo+r+xGqRciE4KyGPl4lB
+yY02/alVEBhHFbAUtpJw22HnWBXYu4ltc0ga/LqUGjlSNgFDBUWuBJ0uzf3tNLjkNLrOMyFaRbsiSpBW
+SHoFi6uHH+Lh2OCEqfNVOccjGuiQzruw1nwnNj6XXnImxBfMBfvpbHk3OC/4Kf+Wx9x13oYnlKaAqDm+
+WO7Wnq56NwJHVwnft7XgdycnjlUMvjz7t2rSNnM2Pq1ueYGPq7YMMv3mCg2zakkPaEEutLsyN1g+06J5
+f8ISY1fx69uxaUvnKre9EC4PWoCHqWA2wS3IEMW8Rji13C+bFS/T4HniE+vCVHpKfejaV80zOAmvXCTQ
+VS0zu4ve3WFH3R6hGEItbHzA4+IwVqWBjN3KQfXzAxcLCGVabC0Z27XjEUnQ58KMmLxp8Mq5hOHGmYcw
+klLj5jOn4AmVWr8yv05o4Zt0opJDAbsnIJci3CZUP+l9drLHayAduMI+tlifW4xTzHYAhlWG1SHQ+Njo
+J7LAf09D1zcE9wathnqDB/FlUb/R8b3oediv5M5/qgBPgCL+yu/xBybFWQgh2Q+vHiuR/u2sqjoE7zZf
+FyrQi5pZWrKpL0iqEFMHVs+9Df7fu8Os2ZSRH02tcaaQBsWqYV9KyIF+9pMWoSzqFM6RHt8nhOBArePf
+MxrS1Eb4OuVdGoHTXVGCm0ptlwN/7EavIMRuqmG0BsLxF+L1aZx9i5XRM5SCnM6Q/GqoPFiRENqtbo4L
+1l6sYfam2+CNFX0wIeHB7P/RIGDnjpj0hMbCGZmVR5PcQkHgt7KdYJEUHTZNTi98ad1Ehue7ABdoeaR7
+1+33PMcTAAB573kPVDXAZwAB3BHYNgAA774vOLHEZ/sCAAAAAARZWv03elhaAAAA/xLZQQTAMdAyIQEW
+AAAAAAAAAAD5k9es4BlPACldAABv/f//o7f/Rz5IFXI5YVG4kijmo4YH5pYBACXx2Ohdo07R0NDANzMA
+AAAAAAABRdAyAAAAcQAXT6gACvwCAAAAAABZWg==`)
+)
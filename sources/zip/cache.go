@@ -0,0 +1,98 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package zip
+
+import "github.com/milochristiansen/axis2"
+
+import "io"
+import "sync"
+import "archive/zip"
+import "container/list"
+
+// cache holds decompressed file contents for NewCachedDir.
+type cache struct {
+	lock  sync.Mutex
+	limit int64
+	used  int64
+	items map[*zip.File]*list.Element
+	order *list.List // Most recently used first.
+}
+
+type cacheItem struct {
+	file *zip.File
+	data []byte
+}
+
+func newCache(limit int64) *cache {
+	return &cache{
+		limit: limit,
+		items: map[*zip.File]*list.Element{},
+		order: list.New(),
+	}
+}
+
+// get returns the decompressed contents of the given file. The returned slice must not be modified.
+func (c *cache) get(file *zip.File) ([]byte, error) {
+	c.lock.Lock()
+	if e, ok := c.items[file]; ok {
+		c.order.MoveToFront(e)
+		c.lock.Unlock()
+		return e.Value.(*cacheItem).data, nil
+	}
+	c.lock.Unlock()
+	
+	if int64(file.UncompressedSize64) > c.limit {
+		return nil, axis2.NewError(axis2.ErrUnsupported)
+	}
+	
+	// Decompress without holding the lock, so other files can be read from the cache in the meantime.
+	r, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	
+	// Someone else may have decompressed the same file while we were working.
+	if e, ok := c.items[file]; ok {
+		c.order.MoveToFront(e)
+		return e.Value.(*cacheItem).data, nil
+	}
+	
+	c.items[file] = c.order.PushFront(&cacheItem{file: file, data: data})
+	c.used += int64(len(data))
+	for c.used > c.limit {
+		e := c.order.Back()
+		item := e.Value.(*cacheItem)
+		c.order.Remove(e)
+		delete(c.items, item.file)
+		c.used -= int64(len(item.data))
+	}
+	return data, nil
+}
@@ -0,0 +1,636 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package zip
+
+import "github.com/milochristiansen/axis2"
+
+import "io"
+import "os"
+import "sort"
+import "sync"
+import "time"
+import "bytes"
+import "strings"
+import "archive/zip"
+import "path/filepath"
+
+// RWDir is a writable AXIS Dir backed by a zip file on disk.
+// 
+// Changes (writes, appends, deletes, and new directories) are staged in memory, and nothing is written to disk until
+// Flush or Close is called. The archive is then rewritten to a temporary file which replaces the original, so the
+// archive on disk is always either entirely the old version or entirely the new version. Unmodified entries are copied
+// over as-is, without being decompressed and recompressed.
+// 
+// A RWDir is safe for concurrent use. Readers that are open while the archive is flushed may fail, so don't keep
+// them open any longer than needed.
+type RWDir struct {
+	lock    sync.Mutex
+	path    string
+	file    *os.File // nil if the archive did not exist when it was opened
+	entries map[string]*rwEntry // Keyed by path, "" is the root.
+	dirty   bool
+}
+
+type rwEntry struct {
+	dir      bool
+	children map[string]bool // Only for directories.
+	
+	// The original entry, nil for new items and directories without an entry of their own. For files this is only
+	// used for the contents if data is nil.
+	zf *zip.File
+	
+	data []byte
+	mode os.FileMode
+	mod  time.Time
+}
+
+type rwDir struct {
+	root *RWDir
+	path string
+}
+
+type rwFile struct {
+	root *RWDir
+	path string
+}
+
+// NewRWDir opens the zip file at the given OS path for reading and writing. If the file does not exist it will be
+// created the first time changes are flushed.
+func NewRWDir(path string) (*RWDir, error) {
+	dir := &RWDir{path: path}
+	err := dir.load()
+	if err != nil {
+		return nil, err
+	}
+	return dir, nil
+}
+
+// open opens the zip file, returning a nil file if it does not exist.
+func (dir *RWDir) open() (*os.File, *zip.Reader, error) {
+	file, err := os.Open(dir.path)
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	z, err := zip.NewReader(file, info.Size())
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	return file, z, nil
+}
+
+// load (re)opens the zip file and builds the entry table from it.
+func (dir *RWDir) load() error {
+	dir.entries = map[string]*rwEntry{
+		"": &rwEntry{dir: true, children: map[string]bool{}, mode: 0777},
+	}
+	
+	file, z, err := dir.open()
+	if file == nil {
+		return err
+	}
+	dir.file = file
+	
+	// Use the same tree as the read-only version to sort things out, then flatten it.
+	var flatten func(tree *zdir, path string)
+	flatten = func(tree *zdir, path string) {
+		for name, item := range tree.items {
+			cpath := join(path, name)
+			dir.entries[path].children[name] = true
+			switch item := item.(type) {
+			case *zdir:
+				e := &rwEntry{dir: true, children: map[string]bool{}, zf: item.me, mode: 0777}
+				if item.me != nil {
+					e.mode, e.mod = item.me.Mode().Perm(), item.me.Modified
+				}
+				dir.entries[cpath] = e
+				flatten(item, cpath)
+			case *zfile:
+				dir.entries[cpath] = &rwEntry{zf: item.me, mode: item.me.Mode().Perm(), mod: item.me.Modified}
+			}
+		}
+	}
+	flatten(mkTree(z, &zarchive{ra: file}), "")
+	return nil
+}
+
+// Flush writes any staged changes to disk. The replacement keeps the permissions of the original file, new archives
+// are created with mode 0644.
+func (dir *RWDir) Flush() error {
+	dir.lock.Lock()
+	defer dir.lock.Unlock()
+	
+	if !dir.dirty {
+		return nil
+	}
+	
+	tmp, err := os.CreateTemp(filepath.Dir(dir.path), "."+filepath.Base(dir.path)+"-*")
+	if err != nil {
+		return err
+	}
+	err = dir.write(tmp)
+	if err == nil {
+		// CreateTemp always uses 0600, so the replacement has to be given the permissions of the file it replaces.
+		perm := os.FileMode(0644)
+		if info, serr := os.Stat(dir.path); serr == nil {
+			perm = info.Mode().Perm()
+		}
+		err = tmp.Chmod(perm)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	
+	// Windows will not replace a file that is still open.
+	if dir.file != nil {
+		dir.file.Close()
+		dir.file = nil
+	}
+	err = os.Rename(tmp.Name(), dir.path)
+	if err != nil {
+		os.Remove(tmp.Name())
+		
+		// Nothing changed on disk, so go back to the old file without losing the staged changes.
+		if rerr := dir.reopen(); rerr != nil {
+			return rerr
+		}
+		return err
+	}
+	
+	// The old file has been replaced, so start over with the new one.
+	dir.dirty = false
+	return dir.load()
+}
+
+// reopen opens the zip file again after it was closed by a failed Flush, pointing every unchanged entry at the new
+// copy. The lock must be held.
+func (dir *RWDir) reopen() error {
+	file, z, err := dir.open()
+	if file == nil {
+		return err
+	}
+	dir.file = file
+	
+	byName := make(map[string]*zip.File, len(z.File))
+	for _, zf := range z.File {
+		byName[zf.Name] = zf
+	}
+	for _, e := range dir.entries {
+		if e.zf != nil {
+			e.zf = byName[e.zf.Name]
+		}
+	}
+	return nil
+}
+
+// Close flushes any staged changes, then closes the zip file. The RWDir must not be used after it is closed.
+func (dir *RWDir) Close() error {
+	err := dir.Flush()
+	
+	dir.lock.Lock()
+	defer dir.lock.Unlock()
+	if dir.file != nil {
+		if cerr := dir.file.Close(); err == nil {
+			err = cerr
+		}
+		dir.file = nil
+	}
+	return err
+}
+
+// write writes the complete archive to w. The lock must be held.
+func (dir *RWDir) write(w io.Writer) error {
+	paths := make([]string, 0, len(dir.entries))
+	for path := range dir.entries {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	
+	zw := zip.NewWriter(w)
+	for _, path := range paths {
+		e := dir.entries[path]
+		
+		var err error
+		switch {
+		case e.dir:
+			// Directories only need an entry of their own if they are empty or had one to start with.
+			if e.zf != nil || len(e.children) == 0 {
+				hdr := &zip.FileHeader{Name: path + "/", Modified: e.mod}
+				hdr.SetMode(os.ModeDir | e.mode)
+				_, err = zw.CreateHeader(hdr)
+			}
+		case e.data == nil && e.zf != nil:
+			// Unchanged, so copy the entry without decompressing it.
+			hdr := e.zf.FileHeader
+			hdr.Name = path
+			hdr.Modified = e.mod
+			hdr.SetMode(e.mode)
+			var raw io.Reader
+			raw, err = e.zf.OpenRaw()
+			if err == nil {
+				var fw io.Writer
+				fw, err = zw.CreateRaw(&hdr)
+				if err == nil {
+					_, err = io.Copy(fw, raw)
+				}
+			}
+		default:
+			hdr := &zip.FileHeader{Name: path, Method: zip.Deflate, Modified: e.mod}
+			hdr.SetMode(e.mode)
+			var fw io.Writer
+			fw, err = zw.CreateHeader(hdr)
+			if err == nil {
+				_, err = fw.Write(e.data)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// mkdirs makes sure the directory at path (and all its parents) exist. The lock must be held.
+func (dir *RWDir) mkdirs(path string) (*rwEntry, error) {
+	if e, ok := dir.entries[path]; ok {
+		if !e.dir {
+			return nil, axis2.NewError(axis2.ErrNotDir)
+		}
+		return e, nil
+	}
+	
+	ppath, name := split(path)
+	parent, err := dir.mkdirs(ppath)
+	if err != nil {
+		return nil, err
+	}
+	e := &rwEntry{dir: true, children: map[string]bool{}, mode: 0777, mod: time.Now()}
+	dir.entries[path] = e
+	parent.children[name] = true
+	dir.dirty = true
+	return e, nil
+}
+
+// contents returns the current contents of a file entry. The lock must be held.
+func (e *rwEntry) contents() ([]byte, error) {
+	if e.data != nil || e.zf == nil {
+		return e.data, nil
+	}
+	
+	r, err := e.zf.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+func (dir *RWDir) child(path, id string, create int) axis2.DataSource {
+	dir.lock.Lock()
+	defer dir.lock.Unlock()
+	
+	cpath := join(path, id)
+	e, ok := dir.entries[cpath]
+	switch {
+	case ok && e.dir, !ok && create == axis2.CreateDir:
+		return rwDir{root: dir, path: cpath}
+	case ok, create == axis2.CreateFile:
+		return rwFile{root: dir, path: cpath}
+	default:
+		return nil
+	}
+}
+
+func (dir *RWDir) delete(path, id string) error {
+	dir.lock.Lock()
+	defer dir.lock.Unlock()
+	
+	cpath := join(path, id)
+	e, ok := dir.entries[cpath]
+	if !ok {
+		return axis2.NewError(axis2.ErrNotFound)
+	}
+	if e.dir && len(e.children) != 0 {
+		return axis2.NewError(axis2.ErrNotEmpty)
+	}
+	delete(dir.entries, cpath)
+	delete(dir.entries[path].children, id)
+	dir.dirty = true
+	return nil
+}
+
+func (dir *RWDir) list(path string) []string {
+	dir.lock.Lock()
+	defer dir.lock.Unlock()
+	
+	e, ok := dir.entries[path]
+	if !ok {
+		return nil
+	}
+	rtn := make([]string, 0, len(e.children))
+	for name := range e.children {
+		rtn = append(rtn, name)
+	}
+	return rtn
+}
+
+func (dir *RWDir) mkdir(path, id string) error {
+	dir.lock.Lock()
+	defer dir.lock.Unlock()
+	
+	_, err := dir.mkdirs(join(path, id))
+	return err
+}
+
+func (dir *RWDir) stat(path string) (os.FileInfo, error) {
+	dir.lock.Lock()
+	defer dir.lock.Unlock()
+	
+	e, ok := dir.entries[path]
+	if !ok {
+		return nil, axis2.NewError(axis2.ErrNotFound)
+	}
+	_, name := split(path)
+	info := &rwInfo{name: name, mode: e.mode, mod: e.mod}
+	if e.dir {
+		info.mode |= os.ModeDir
+	} else if e.data != nil || e.zf == nil {
+		info.size = int64(len(e.data))
+	} else {
+		info.size = int64(e.zf.UncompressedSize64)
+	}
+	return info, nil
+}
+
+func (dir *RWDir) setMeta(path string, perm os.FileMode, modTime time.Time) error {
+	dir.lock.Lock()
+	defer dir.lock.Unlock()
+	
+	e, ok := dir.entries[path]
+	if !ok {
+		return axis2.NewError(axis2.ErrNotFound)
+	}
+	e.mode = perm.Perm()
+	if !modTime.IsZero() {
+		e.mod = modTime
+	}
+	dir.dirty = true
+	return nil
+}
+
+// Child, Delete, List, Mkdir, Stat, and SetMeta for the root are simply forwarded to the internal implementations.
+
+// Describe returns the OS path of the zip file.
+func (dir *RWDir) Describe() string {
+	return dir.path
+}
+
+func (dir *RWDir) Child(id string, create int) axis2.DataSource {
+	return dir.child("", id, create)
+}
+
+func (dir *RWDir) Delete(id string) error {
+	return dir.delete("", id)
+}
+
+func (dir *RWDir) List() []string {
+	return dir.list("")
+}
+
+func (dir *RWDir) Mkdir(id string) error {
+	return dir.mkdir("", id)
+}
+
+func (dir *RWDir) Stat() (os.FileInfo, error) {
+	return dir.stat("")
+}
+
+func (dir *RWDir) SetMeta(perm os.FileMode, modTime time.Time) error {
+	return dir.setMeta("", perm, modTime)
+}
+
+func (dir rwDir) Describe() string {
+	return dir.root.path + ":" + dir.path
+}
+
+func (dir rwDir) Child(id string, create int) axis2.DataSource {
+	return dir.root.child(dir.path, id, create)
+}
+
+func (dir rwDir) Delete(id string) error {
+	return dir.root.delete(dir.path, id)
+}
+
+func (dir rwDir) List() []string {
+	return dir.root.list(dir.path)
+}
+
+func (dir rwDir) Mkdir(id string) error {
+	return dir.root.mkdir(dir.path, id)
+}
+
+func (dir rwDir) Stat() (os.FileInfo, error) {
+	return dir.root.stat(dir.path)
+}
+
+func (dir rwDir) SetMeta(perm os.FileMode, modTime time.Time) error {
+	return dir.root.setMeta(dir.path, perm, modTime)
+}
+
+func (file rwFile) Describe() string {
+	return file.root.path + ":" + file.path
+}
+
+func (file rwFile) Size() int64 {
+	info, err := file.root.stat(file.path)
+	if err != nil {
+		return -1
+	}
+	return info.Size()
+}
+
+func (file rwFile) Stat() (os.FileInfo, error) {
+	return file.root.stat(file.path)
+}
+
+func (file rwFile) SetMeta(perm os.FileMode, modTime time.Time) error {
+	return file.root.setMeta(file.path, perm, modTime)
+}
+
+func (file rwFile) Read() (io.ReadCloser, error) {
+	file.root.lock.Lock()
+	defer file.root.lock.Unlock()
+	
+	e, ok := file.root.entries[file.path]
+	if !ok || e.dir {
+		return nil, axis2.NewError(axis2.ErrNotFound)
+	}
+	if e.data != nil || e.zf == nil {
+		return io.NopCloser(bytes.NewReader(e.data)), nil
+	}
+	return e.zf.Open()
+}
+
+// OpenRandom returns the staged contents if the file has been changed since the archive was loaded, otherwise it
+// reads the whole file into memory.
+func (file rwFile) OpenRandom() (axis2.RandomReader, error) {
+	file.root.lock.Lock()
+	defer file.root.lock.Unlock()
+	
+	e, ok := file.root.entries[file.path]
+	if !ok || e.dir {
+		return nil, axis2.NewError(axis2.ErrNotFound)
+	}
+	data, err := e.contents()
+	if err != nil {
+		return nil, err
+	}
+	return nopCloser{bytes.NewReader(data)}, nil
+}
+
+func (file rwFile) Write() (io.WriteCloser, error) {
+	return &rwWriter{file: file}, nil
+}
+
+func (file rwFile) Append() (io.WriteCloser, error) {
+	return &rwWriter{file: file, append: true}, nil
+}
+
+// rwWriter buffers written data until it is closed, then stages it.
+type rwWriter struct {
+	file   rwFile
+	buf    bytes.Buffer
+	append bool
+	closed bool
+}
+
+func (w *rwWriter) Write(b []byte) (int, error) {
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	return w.buf.Write(b)
+}
+
+func (w *rwWriter) Close() error {
+	if w.closed {
+		return os.ErrClosed
+	}
+	w.closed = true
+	
+	root := w.file.root
+	root.lock.Lock()
+	defer root.lock.Unlock()
+	
+	ppath, name := split(w.file.path)
+	parent, err := root.mkdirs(ppath)
+	if err != nil {
+		return err
+	}
+	
+	e, ok := root.entries[w.file.path]
+	if !ok {
+		e = &rwEntry{mode: 0666}
+		root.entries[w.file.path] = e
+		parent.children[name] = true
+	}
+	if e.dir {
+		return axis2.NewError(axis2.ErrIsDir)
+	}
+	
+	data := w.buf.Bytes()
+	if w.append {
+		old, err := e.contents()
+		if err != nil {
+			return err
+		}
+		data = append(append([]byte{}, old...), data...)
+	}
+	e.data = append([]byte{}, data...)
+	e.mod = time.Now()
+	root.dirty = true
+	return nil
+}
+
+func join(path, id string) string {
+	if path == "" {
+		return id
+	}
+	return path + "/" + id
+}
+
+func split(path string) (string, string) {
+	i := strings.LastIndex(path, "/")
+	if i == -1 {
+		return "", path
+	}
+	return path[:i], path[i+1:]
+}
+
+// rwInfo is the os.FileInfo for items in a RWDir.
+type rwInfo struct {
+	name string
+	size int64
+	mode os.FileMode
+	mod  time.Time
+}
+
+func (info *rwInfo) Name() string {
+	return info.name
+}
+
+func (info *rwInfo) Size() int64 {
+	return info.size
+}
+
+func (info *rwInfo) Mode() os.FileMode {
+	return info.mode
+}
+
+func (info *rwInfo) ModTime() time.Time {
+	return info.mod
+}
+
+func (info *rwInfo) IsDir() bool {
+	return info.mode.IsDir()
+}
+
+func (info *rwInfo) Sys() interface{} {
+	return nil
+}
@@ -0,0 +1,291 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package zip_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	
+	"github.com/milochristiansen/axis2"
+	axiszip "github.com/milochristiansen/axis2/sources/zip"
+)
+
+// mkZip writes a zip file for testing to a new temporary directory. b.txt is stored without compression and has a
+// comment, so that raw copies can be told apart from rewritten entries.
+func mkZip(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "test.zip")
+	
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, hdr := range []*zip.FileHeader{
+		{Name: "a/x.txt", Method: zip.Deflate},
+		{Name: "b.txt", Method: zip.Store, Comment: "raw"},
+		{Name: "c.txt", Method: zip.Deflate},
+	} {
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(hdr.Name))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0666); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// onDisk reads the zip file at path, returning the entries by name.
+func onDisk(t *testing.T, path string) map[string]*zip.File {
+	t.Helper()
+	
+	z, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { z.Close() })
+	
+	rtn := map[string]*zip.File{}
+	for _, zf := range z.File {
+		rtn[zf.Name] = zf
+	}
+	return rtn
+}
+
+func TestRWDir(t *testing.T) {
+	path := mkZip(t)
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	
+	ds, err := axiszip.NewRWDir(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ds.Close()
+	fs := new(axis2.FileSystem)
+	fs.Mount("", ds, true)
+	
+	// Stage some changes.
+	if err := fs.WriteAll("d/new.txt", []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	w, err := fs.Append("c.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(" more"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Delete("b.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Delete("a"); !errors.Is(err, axis2.ErrNotEmptySentinel) {
+		t.Errorf("deleting a non-empty directory: unexpected error: %v", err)
+	}
+	
+	// The changes are visible, but nothing is written yet.
+	now, _ := os.ReadFile(path)
+	if !bytes.Equal(before, now) {
+		t.Error("staged changes were written to disk before Flush")
+	}
+	for name, want := range map[string]string{"d/new.txt": "new", "c.txt": "c.txt more", "a/x.txt": "a/x.txt"} {
+		content, err := fs.ReadAll(name)
+		if err != nil || string(content) != want {
+			t.Errorf("%v: got %q (%v), want %q", name, content, err, want)
+		}
+		if fs.Size(name) != int64(len(want)) {
+			t.Errorf("%v: unexpected size %v", name, fs.Size(name))
+		}
+	}
+	if fs.Exists("b.txt") {
+		t.Error("b.txt still exists after delete")
+	}
+	
+	// OpenRandom sees staged data too.
+	r, err := fs.OpenRandom("c.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	if _, err := r.ReadAt(buf, 6); err != nil || string(buf) != "more" {
+		t.Errorf("unexpected random access result: %q (%v)", buf, err)
+	}
+	r.Close()
+	
+	if err := ds.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	
+	zfs := onDisk(t, path)
+	if _, ok := zfs["b.txt"]; ok {
+		t.Error("deleted file is still in the archive")
+	}
+	for name, want := range map[string]string{"d/new.txt": "new", "c.txt": "c.txt more", "a/x.txt": "a/x.txt"} {
+		zf, ok := zfs[name]
+		if !ok {
+			t.Errorf("%v: not in the archive", name)
+			continue
+		}
+		zr, err := zf.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(zr)
+		zr.Close()
+		if err != nil || string(content) != want {
+			t.Errorf("%v: got %q (%v) from the archive, want %q", name, content, err, want)
+		}
+	}
+	
+	// No temporary files are left behind.
+	files, _ := os.ReadDir(filepath.Dir(path))
+	if len(files) != 1 {
+		t.Errorf("unexpected files after Flush: %v", files)
+	}
+	
+	// The archive was reopened, so reading and writing still work.
+	content, err := fs.ReadAll("a/x.txt")
+	if err != nil || string(content) != "a/x.txt" {
+		t.Errorf("reading after Flush: got %q (%v)", content, err)
+	}
+	if err := fs.WriteAll("e.txt", []byte("e")); err != nil {
+		t.Fatal(err)
+	}
+	if err := ds.Close(); err != nil {
+		t.Fatal(err)
+	}
+	
+	ds2, err := axiszip.NewRWDir(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ds2.Close()
+	fs2 := new(axis2.FileSystem)
+	fs2.Mount("", ds2, true)
+	for name, want := range map[string]string{"d/new.txt": "new", "c.txt": "c.txt more", "a/x.txt": "a/x.txt", "e.txt": "e"} {
+		content, err := fs2.ReadAll(name)
+		if err != nil || string(content) != want {
+			t.Errorf("%v: got %q (%v) after reopening, want %q", name, content, err, want)
+		}
+	}
+}
+
+func TestRWDirRawCopy(t *testing.T) {
+	path := mkZip(t)
+	orig := onDisk(t, path)["b.txt"]
+	
+	ds, err := axiszip.NewRWDir(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs := new(axis2.FileSystem)
+	fs.Mount("", ds, true)
+	if err := fs.WriteAll("c.txt", []byte("changed")); err != nil {
+		t.Fatal(err)
+	}
+	if err := ds.Close(); err != nil {
+		t.Fatal(err)
+	}
+	
+	// Rewritten entries are always compressed, so b.txt keeping its method and comment means it was copied as-is.
+	zfs := onDisk(t, path)
+	zf := zfs["b.txt"]
+	if zf == nil {
+		t.Fatal("b.txt is missing")
+	}
+	if zf.Method != zip.Store || zf.Comment != "raw" || zf.CRC32 != orig.CRC32 {
+		t.Errorf("b.txt was not copied as-is: method %v, comment %q", zf.Method, zf.Comment)
+	}
+	if zfs["c.txt"].Method != zip.Deflate {
+		t.Errorf("c.txt was not rewritten")
+	}
+}
+
+func TestRWDirNew(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "new.zip")
+	ds, err := axiszip.NewRWDir(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	
+	// Nothing is created until there is something to write.
+	if err := ds.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("archive created without changes: %v", err)
+	}
+	
+	fs := new(axis2.FileSystem)
+	fs.Mount("", ds, true)
+	if err := fs.MkdirAll("x/y"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ds.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := onDisk(t, path)["x/y/"]; !ok {
+		t.Error("empty directory was not written")
+	}
+}
+
+func TestRWDirMode(t *testing.T) {
+	path := mkZip(t)
+	if err := os.Chmod(path, 0640); err != nil {
+		t.Fatal(err)
+	}
+	newPath := filepath.Join(t.TempDir(), "new.zip")
+	
+	for path, want := range map[string]os.FileMode{path: 0640, newPath: 0644} {
+		ds, err := axiszip.NewRWDir(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fs := new(axis2.FileSystem)
+		fs.Mount("", ds, true)
+		if err := fs.WriteAll("new.txt", []byte("new")); err != nil {
+			t.Fatal(err)
+		}
+		if err := ds.Close(); err != nil {
+			t.Fatal(err)
+		}
+		
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != want {
+			t.Errorf("%v: mode is %v, not %v", filepath.Base(path), info.Mode().Perm(), want)
+		}
+	}
+}
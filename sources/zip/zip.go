@@ -25,27 +25,43 @@ package zip
 import "github.com/milochristiansen/axis2"
 
 import "io"
+import "os"
+import "time"
 import "bytes"
 import "strings"
 import "archive/zip"
 
+// zarchive holds the information shared by every item in a zip file.
+type zarchive struct {
+	ra    io.ReaderAt
+	cache *cache // nil if compressed files do not support random access.
+	name  string // Used by Describe, empty if not known.
+}
+
 type zdir struct {
 	items map[string]interface{} // Either *zdir or *zfile
-	zip   *zip.Reader
+	arc   *zarchive
+	path  string // The full path inside the archive, empty for the root.
+	name  string
+	me    *zip.File // nil if the directory has no entry of its own
+	
+	closer io.Closer // Only set for the root, and only by OpenFile.
 }
 
 type zfile struct {
 	me  *zip.File
-	zip *zip.Reader
+	arc *zarchive
 }
 
 // NewDir creates a read-only AXIS Dir backed by a zip file.
+// 
+// If file has a Name method (like *os.File) the name is used to describe the Dir (see axis2.Describer).
 func NewDir(file io.ReaderAt, size int64) (axis2.Dir, error) {
 	z, err := zip.NewReader(file, size)
 	if err != nil {
 		return nil, err
 	}
-	return mkTree(z), nil
+	return mkTree(z, &zarchive{ra: file, name: nameOf(file)}), nil
 }
 
 // NewRawDir creates a read-only AXIS Dir backed by a zip file that has been read into memory.
@@ -56,15 +72,79 @@ func NewRawDir(content []byte) (axis2.Dir, error) {
 	if err != nil {
 		return nil, err
 	}
-	return mkTree(z), nil
+	return mkTree(z, &zarchive{ra: file}), nil
+}
+
+// OpenFile creates a read-only AXIS Dir from a zip file stored in an AXIS File. It is intended for use with
+// FileSystem.AutoMount, for example:
+// 
+//	fs.AutoMount(".zip", zip.OpenFile)
+// 
+// If the File implements RandomAccess the zip file is read directly from the File, otherwise the whole file is read
+// into memory. The returned Dir implements io.Closer, and is described using the File's description (if it has one).
+func OpenFile(file axis2.File) (axis2.Dir, error) {
+	name := nameOf(file)
+	if ra, ok := file.(axis2.RandomAccess); ok {
+		r, err := ra.OpenRandom()
+		if err == nil {
+			z, err := zip.NewReader(r, file.Size())
+			if err != nil {
+				r.Close()
+				return nil, err
+			}
+			dir := mkTree(z, &zarchive{ra: r, name: name})
+			dir.closer = r
+			return dir, nil
+		}
+	}
+	
+	r, err := file.Read()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	
+	file2 := bytes.NewReader(content)
+	z, err := zip.NewReader(file2, int64(file2.Len()))
+	if err != nil {
+		return nil, err
+	}
+	return mkTree(z, &zarchive{ra: file2, name: name}), nil
+}
+
+// NewCachedDir is like NewDir, except compressed files support random access (see axis2.RandomAccess). This is done
+// by decompressing the whole file into memory when it is opened for random access. The decompressed data is cached
+// so later calls do not need to decompress it again, with the least recently used files being dropped from the cache
+// when it holds more than limit bytes. Files larger than limit do not support random access.
+func NewCachedDir(file io.ReaderAt, size int64, limit int64) (axis2.Dir, error) {
+	z, err := zip.NewReader(file, size)
+	if err != nil {
+		return nil, err
+	}
+	return mkTree(z, &zarchive{ra: file, cache: newCache(limit), name: nameOf(file)}), nil
+}
+
+// nameOf returns a description for a file the archive is read from, or an empty string if there isn't one.
+func nameOf(file interface{}) string {
+	switch f := file.(type) {
+	case axis2.Describer:
+		return f.Describe()
+	case interface{ Name() string }:
+		return f.Name()
+	}
+	return ""
 }
 
 // Since zip files are assumed readonly I generate a static tree of dir and file objects when opening the zip.
 // This makes file lookup much faster.
-func mkTree(z *zip.Reader) *zdir {
+func mkTree(z *zip.Reader, arc *zarchive) *zdir {
 	base := &zdir{
 		items: map[string]interface{}{},
-		zip: z,
+		arc: arc,
 	}
 	
 	for _, file := range z.File {
@@ -76,21 +156,31 @@ func mkTree(z *zip.Reader) *zdir {
 				// This will almost certainly never trigger.
 				child = &zdir{
 					items: map[string]interface{}{},
-					zip: z,
+					arc: arc,
+					path: strings.Join(parts[:i+1], "/"),
+					name: parts[i],
 				}
 				dir.items[parts[i]] = child
 			}
 			dir = child.(*zdir)
 		}
 		if file.FileInfo().IsDir() {
+			// Don't clobber the directory if one of its children was listed first.
+			if child, ok := dir.items[parts[len(parts)-1]].(*zdir); ok {
+				child.me = file
+				continue
+			}
 			dir.items[parts[len(parts)-1]] = &zdir{
 				items: map[string]interface{}{},
-				zip: z,
+				arc: arc,
+				path: strings.Join(parts, "/"),
+				name: parts[len(parts)-1],
+				me: file,
 			}
 		} else {
 			dir.items[parts[len(parts)-1]] = &zfile{
 				me: file,
-				zip: z,
+				arc: arc,
 			}
 		}
 	}
@@ -101,10 +191,34 @@ func (dir *zdir) Child(id string, create int) axis2.DataSource {
 	return dir.items[id]
 }
 
+func (dir *zdir) Stat() (os.FileInfo, error) {
+	if dir.me != nil {
+		return dir.me.FileInfo(), nil
+	}
+	return dirInfo(dir.name), nil
+}
+
+// Describe returns the name of the archive, followed by the path of the directory inside it.
+func (dir *zdir) Describe() string {
+	return dir.arc.describe(dir.path)
+}
+
+// Close closes the underlying File if the Dir was created by OpenFile.
+func (dir *zdir) Close() error {
+	if dir.closer != nil {
+		return dir.closer.Close()
+	}
+	return nil
+}
+
 func (dir *zdir) Delete(id string) error {
 	return axis2.NewError(axis2.ErrReadOnly)
 }
 
+func (dir *zdir) Mkdir(id string) error {
+	return axis2.NewError(axis2.ErrReadOnly)
+}
+
 func (dir *zdir) List() []string {
 	var rtn []string
 	for n := range dir.items {
@@ -117,10 +231,40 @@ func (file *zfile) Size() int64 {
 	return int64(file.me.UncompressedSize64)
 }
 
+func (file *zfile) Stat() (os.FileInfo, error) {
+	return file.me.FileInfo(), nil
+}
+
+// Describe returns the name of the archive, followed by the path of the file inside it.
+func (file *zfile) Describe() string {
+	return file.arc.describe(file.me.Name)
+}
+
 func (file *zfile) Read() (io.ReadCloser, error) {
 	return file.me.Open()
 }
 
+// OpenRandom is only supported for files that are stored without compression, unless the Dir was created with
+// NewCachedDir.
+func (file *zfile) OpenRandom() (axis2.RandomReader, error) {
+	if file.me.Method != zip.Store {
+		if file.arc.cache == nil {
+			return nil, axis2.NewError(axis2.ErrUnsupported)
+		}
+		data, err := file.arc.cache.get(file.me)
+		if err != nil {
+			return nil, err
+		}
+		return nopCloser{bytes.NewReader(data)}, nil
+	}
+	
+	offset, err := file.me.DataOffset()
+	if err != nil {
+		return nil, err
+	}
+	return nopCloser{io.NewSectionReader(file.arc.ra, offset, int64(file.me.UncompressedSize64))}, nil
+}
+
 func (file *zfile) Write() (io.WriteCloser, error) {
 	return nil, axis2.NewError(axis2.ErrReadOnly)
 }
@@ -128,3 +272,57 @@ func (file *zfile) Write() (io.WriteCloser, error) {
 func (file *zfile) Append() (io.WriteCloser, error) {
 	return nil, axis2.NewError(axis2.ErrReadOnly)
 }
+
+// describe returns a description of the item with the given path inside the archive.
+func (arc *zarchive) describe(path string) string {
+	name := arc.name
+	if name == "" {
+		name = "(zip)"
+	}
+	if path == "" {
+		return name
+	}
+	return name + ":" + path
+}
+
+// nopCloser adds a Close method that does nothing to a bytes.Reader or io.SectionReader.
+type nopCloser struct {
+	readSeekerAt
+}
+
+type readSeekerAt interface {
+	io.Reader
+	io.ReaderAt
+	io.Seeker
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// dirInfo is the os.FileInfo for directories that do not have an entry of their own.
+type dirInfo string
+
+func (info dirInfo) Name() string {
+	return string(info)
+}
+
+func (info dirInfo) Size() int64 {
+	return 0
+}
+
+func (info dirInfo) Mode() os.FileMode {
+	return os.ModeDir | 0777
+}
+
+func (info dirInfo) ModTime() time.Time {
+	return time.Time{}
+}
+
+func (info dirInfo) IsDir() bool {
+	return true
+}
+
+func (info dirInfo) Sys() interface{} {
+	return nil
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+
+package axis2_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	
+	"github.com/milochristiansen/axis2"
+	"github.com/milochristiansen/axis2/sources"
+	axiszip "github.com/milochristiansen/axis2/sources/zip"
+)
+
+// bareDir hides everything except the Dir interface from itself and its children (so there is no Stater).
+type bareDir struct {
+	axis2.Dir
+}
+
+func (d bareDir) Child(id string, create int) axis2.DataSource {
+	switch c := d.Dir.Child(id, create).(type) {
+	case axis2.Dir:
+		return bareDir{c}
+	case axis2.File:
+		return bareFile{c}
+	}
+	return nil
+}
+
+// bareFile hides everything except the File interface.
+type bareFile struct {
+	axis2.File
+}
+
+func TestStatSynthesized(t *testing.T) {
+	ds := memDir(t, map[string]string{"x.txt": "12345", "a/y.txt": ""})
+	afs := new(axis2.FileSystem)
+	afs.Mount("ro", bareDir{ds}, false)
+	afs.Mount("rw", bareDir{ds}, true)
+	
+	tests := []struct {
+		path  string
+		mode  os.FileMode
+		size  int64
+		isDir bool
+	}{
+		{"ro/x.txt", 0444, 5, false},
+		{"ro/a", os.ModeDir | 0555, 0, true},
+		{"rw/x.txt", 0644, 5, false},
+		{"rw/a", os.ModeDir | 0755, 0, true},
+	}
+	for _, test := range tests {
+		info, err := afs.Stat(test.path)
+		if err != nil {
+			t.Errorf("%v: %v", test.path, err)
+			continue
+		}
+		if info.Mode != test.mode || info.Size != test.size || info.IsDir != test.isDir || !info.ModTime.IsZero() {
+			t.Errorf("%v: unexpected result: %#v", test.path, info)
+		}
+	}
+}
+
+func TestStatMountPoints(t *testing.T) {
+	ds := memDir(t, map[string]string{"x.txt": ""})
+	afs := new(axis2.FileSystem)
+	afs.Mount("a/b/c", ds, false)
+	
+	for _, path := range []string{"", "a", "a/b"} {
+		info, err := afs.Stat(path)
+		if err != nil {
+			t.Errorf("%q: %v", path, err)
+			continue
+		}
+		if !info.IsDir || !info.IsMP || info.Mode != os.ModeDir|0555 || info.MountPoint != "" || info.Source != nil {
+			t.Errorf("%q: unexpected result for a mount point subset: %#v", path, info)
+		}
+	}
+	
+	for _, path := range []string{"a/b/c", "a/b/c/x.txt"} {
+		info, err := afs.Stat(path)
+		if err != nil {
+			t.Errorf("%q: %v", path, err)
+			continue
+		}
+		if info.MountPoint != "a/b/c" || info.Source != ds {
+			t.Errorf("%q: MountPoint or Source is wrong: %#v", path, info)
+		}
+	}
+	if info, _ := afs.Stat("a/b/c/x.txt"); info == nil || info.Name != "x.txt" || info.IsDir || info.IsMP {
+		t.Errorf("unexpected result for a file: %#v", info)
+	}
+	
+	if _, err := afs.Stat("a/x"); err == nil {
+		t.Error("Stat of a missing item did not fail")
+	}
+}
+
+func TestStatModTime(t *testing.T) {
+	when := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "x.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filepath.Join(dir, "x.txt"), when, when); err != nil {
+		t.Fatal(err)
+	}
+	
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: "x.txt", Modified: when})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("x"))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zds, err := axiszip.NewRawDir(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	
+	afs := new(axis2.FileSystem)
+	afs.Mount("os", sources.NewOSDir(dir), false)
+	afs.Mount("zip", zds, false)
+	
+	for _, path := range []string{"os/x.txt", "zip/x.txt"} {
+		info, err := afs.Stat(path)
+		if err != nil {
+			t.Errorf("%v: %v", path, err)
+			continue
+		}
+		if !info.ModTime.Equal(when) || info.Size != 1 {
+			t.Errorf("%v: unexpected result: %#v", path, info)
+		}
+	}
+}
@@ -0,0 +1,466 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package axis2
+
+import "io"
+import iofs "io/fs"
+import "sort"
+import "strings"
+
+// View is a FileSystem confined to a single directory, as returned by FileSystem.Sub.
+// 
+// Paths given to a View are relative to its prefix, and since AXIS paths cannot contain ".." there is no way to reach
+// anything outside of it. Paths in returned errors (and passed to WalkFuncs) are relative to the prefix as well, and
+// neither errors nor FileInfos include DataSources mounted outside of it (or any DataSources at all if the View is
+// read-only, see SourceError and FileInfo).
+// 
+// The root of a View cannot be deleted or renamed, since that would change things outside of it. RemoveAll empties it
+// instead.
+// 
+// A View has no mount table of its own, it always uses the current mount table of its FileSystem, so anything
+// mounted (or unmounted) later is reflected in the View right away.
+type View struct {
+	fs     *FileSystem
+	prefix string
+	ro     bool
+}
+
+// Sub returns a View of the FileSystem that only contains the items under the given prefix. The prefix does not
+// need to exist yet.
+func (fs *FileSystem) Sub(prefix string) (*View, error) {
+	dirs := validatePath(prefix)
+	if dirs == nil {
+		return nil, &Error{Path: prefix, Typ: ErrBadPath}
+	}
+	return &View{fs: fs, prefix: strings.Join(dirs, "/")}, nil
+}
+
+// Sub returns a View that only contains the items under the given prefix (relative to this View's prefix).
+// If this View is read-only the new one will be as well.
+func (v *View) Sub(prefix string) (*View, error) {
+	path, err := v.path(prefix)
+	if err != nil {
+		return nil, err
+	}
+	return &View{fs: v.fs, prefix: path, ro: v.ro}, nil
+}
+
+// ReadOnly returns a read-only copy of the View. Any attempt to change something through a read-only View returns an
+// error of type ErrReadOnly, and Stat reports that nothing is writable.
+func (v *View) ReadOnly() *View {
+	return &View{fs: v.fs, prefix: v.prefix, ro: true}
+}
+
+// path converts a View path to a FileSystem path.
+func (v *View) path(path string) (string, error) {
+	dirs := validatePath(path)
+	if dirs == nil {
+		return "", &Error{Path: path, Typ: ErrBadPath}
+	}
+	return joinPath(v.prefix, strings.Join(dirs, "/")), nil
+}
+
+// inside returns true if the FileSystem path is the View's prefix, or inside it.
+func (v *View) inside(path string) bool {
+	return v.prefix == "" || path == v.prefix || strings.HasPrefix(path, v.prefix+"/")
+}
+
+// rel converts a FileSystem path to a View path. Paths outside the View become its root.
+func (v *View) rel(path string) string {
+	if !v.inside(path) {
+		return ""
+	}
+	if path == v.prefix || v.prefix == "" {
+		return strings.TrimPrefix(path, v.prefix)
+	}
+	return strings.TrimPrefix(path, v.prefix+"/")
+}
+
+// hides returns true if a DataSource mounted at the given FileSystem path must not be exposed by the View, either
+// because it is mounted outside of it, or because the View is read-only (and the DataSource could be written to).
+func (v *View) hides(mp string) bool {
+	return v.ro || !v.inside(mp)
+}
+
+// info makes a FileInfo returned by the FileSystem relative to the View, removing the DataSource if it is hidden.
+func (v *View) info(info *FileInfo) *FileInfo {
+	if v.ro {
+		info.Mode &^= 0222
+	}
+	if info.Source != nil && v.hides(info.MountPoint) {
+		info.Source = nil
+		info.MountPoint = ""
+	}
+	info.MountPoint = v.rel(info.MountPoint)
+	return info
+}
+
+// err makes the paths in an error returned by the FileSystem relative to the View, including the paths in any errors
+// it wraps. SourceErrors for hidden DataSources (see hides) are replaced with the errors they wrap, so nothing outside
+// the prefix is exposed.
+func (v *View) err(err error) error {
+	switch e := err.(type) {
+	case *Error:
+		rtn := *e
+		rtn.Path = v.rel(e.Path)
+		rtn.Err = v.err(e.Err)
+		return &rtn
+	case *SourceError:
+		if v.hides(e.MountPoint) {
+			return v.err(e.Err)
+		}
+		rtn := *e
+		rtn.MountPoint = v.rel(e.MountPoint)
+		rtn.Err = v.err(e.Err)
+		return &rtn
+	case MultiError:
+		rtn := make(MultiError, 0, len(e))
+		for _, err := range e {
+			rtn = append(rtn, v.err(err))
+		}
+		return rtn
+	}
+	return err
+}
+
+// write returns an error if the View is read-only.
+func (v *View) write(path string) error {
+	if v.ro {
+		return &Error{Path: path, Typ: ErrReadOnly}
+	}
+	return nil
+}
+
+// Exists is the same as FileSystem.Exists.
+func (v *View) Exists(path string) bool {
+	p, err := v.path(path)
+	return err == nil && v.fs.Exists(p)
+}
+
+// IsDir is the same as FileSystem.IsDir.
+func (v *View) IsDir(path string) bool {
+	p, err := v.path(path)
+	return err == nil && v.fs.IsDir(p)
+}
+
+// IsMP is the same as FileSystem.IsMP.
+func (v *View) IsMP(path string) bool {
+	p, err := v.path(path)
+	return err == nil && v.fs.IsMP(p)
+}
+
+// Size is the same as FileSystem.Size.
+func (v *View) Size(path string) int64 {
+	p, err := v.path(path)
+	if err != nil {
+		return -1
+	}
+	return v.fs.Size(p)
+}
+
+// Stat is the same as FileSystem.Stat.
+func (v *View) Stat(path string) (*FileInfo, error) {
+	p, err := v.path(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := v.fs.Stat(p)
+	if err != nil {
+		return nil, v.err(err)
+	}
+	return v.info(info), nil
+}
+
+// List is the same as FileSystem.List.
+func (v *View) List(path string) []string {
+	p, err := v.path(path)
+	if err != nil {
+		return nil
+	}
+	return v.fs.List(p)
+}
+
+// ListDirs is the same as FileSystem.ListDirs.
+func (v *View) ListDirs(path string) []string {
+	p, err := v.path(path)
+	if err != nil {
+		return nil
+	}
+	return v.fs.ListDirs(p)
+}
+
+// ListFiles is the same as FileSystem.ListFiles.
+func (v *View) ListFiles(path string) []string {
+	p, err := v.path(path)
+	if err != nil {
+		return nil
+	}
+	return v.fs.ListFiles(p)
+}
+
+// Walk is the same as FileSystem.Walk.
+func (v *View) Walk(root string, fn WalkFunc) error {
+	p, err := v.path(root)
+	if err != nil {
+		return skipOK(fn(root, nil, err))
+	}
+	return v.fs.Walk(p, func(path string, info *FileInfo, err error) error {
+		if info != nil {
+			info = v.info(info)
+		}
+		return fn(v.rel(path), info, v.err(err))
+	})
+}
+
+// WalkDir is the same as FileSystem.WalkDir.
+func (v *View) WalkDir(root string, fn iofs.WalkDirFunc) error {
+	return v.Walk(root, func(path string, info *FileInfo, err error) error {
+		if info == nil {
+			return fn(path, nil, err)
+		}
+		return fn(path, newIOInfo(info.Name, info), err)
+	})
+}
+
+// Glob is the same as FileSystem.Glob.
+func (v *View) Glob(pattern string) ([]string, error) {
+	pats := parseGlob(pattern)
+	if pats == nil {
+		return nil, &Error{Path: pattern, Typ: ErrBadPath}
+	}
+	
+	have := map[string]bool{}
+	var rtn []string
+	v.fs.glob(v.prefix, pats, have, &rtn)
+	for i := range rtn {
+		rtn[i] = v.rel(rtn[i])
+	}
+	sort.Strings(rtn)
+	return rtn, nil
+}
+
+// Read is the same as FileSystem.Read.
+func (v *View) Read(path string) (io.ReadCloser, error) {
+	p, err := v.path(path)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := v.fs.Read(p)
+	return rc, v.err(err)
+}
+
+// OpenRandom is the same as FileSystem.OpenRandom.
+func (v *View) OpenRandom(path string) (RandomReader, error) {
+	p, err := v.path(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := v.fs.OpenRandom(p)
+	return r, v.err(err)
+}
+
+// ReadAll is the same as FileSystem.ReadAll.
+func (v *View) ReadAll(path string) ([]byte, error) {
+	p, err := v.path(path)
+	if err != nil {
+		return nil, err
+	}
+	content, err := v.fs.ReadAll(p)
+	return content, v.err(err)
+}
+
+// Write is the same as FileSystem.Write.
+func (v *View) Write(path string) (io.WriteCloser, error) {
+	p, err := v.path(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.write(path); err != nil {
+		return nil, err
+	}
+	wc, err := v.fs.Write(p)
+	return wc, v.err(err)
+}
+
+// Append is the same as FileSystem.Append.
+func (v *View) Append(path string) (io.WriteCloser, error) {
+	p, err := v.path(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.write(path); err != nil {
+		return nil, err
+	}
+	wc, err := v.fs.Append(p)
+	return wc, v.err(err)
+}
+
+// WriteAll is the same as FileSystem.WriteAll.
+func (v *View) WriteAll(path string, content []byte) error {
+	p, err := v.path(path)
+	if err != nil {
+		return err
+	}
+	if err := v.write(path); err != nil {
+		return err
+	}
+	return v.err(v.fs.WriteAll(p, content))
+}
+
+// Delete is the same as FileSystem.Delete, except that the root of the View cannot be deleted.
+func (v *View) Delete(path string) error {
+	p, err := v.path(path)
+	if err != nil {
+		return err
+	}
+	if err := v.write(path); err != nil {
+		return err
+	}
+	if p == v.prefix {
+		return &Error{Path: path, Typ: ErrBadPath}
+	}
+	return v.err(v.fs.Delete(p))
+}
+
+// RemoveAll is the same as FileSystem.RemoveAll. If the path is the root of the View it is emptied.
+func (v *View) RemoveAll(path string) error {
+	p, err := v.path(path)
+	if err != nil {
+		return err
+	}
+	if err := v.write(path); err != nil {
+		return err
+	}
+	if p != v.prefix {
+		return v.err(v.fs.RemoveAll(p))
+	}
+	
+	// Items may be on the write half only, so list that as well as the read half.
+	names := v.fs.List(p)
+	if dss, err := v.fs.GetDSsAt(p, false, false); err == nil {
+		for _, ds := range dss {
+			if d, ok := ds.(Dir); ok {
+				names = append(names, d.List()...)
+			}
+		}
+	}
+	names = append(names, v.fs.mountSubset(p, false)...)
+	
+	// Whiteout markers are left alone, removing them would make the items they hide visible again.
+	t := v.fs.table.Load()
+	markers := t != nil && t.whiteouts
+	
+	var errs MultiError
+	have := map[string]bool{}
+	for _, name := range names {
+		if have[name] || (markers && strings.HasPrefix(name, WhiteoutPrefix)) {
+			continue
+		}
+		have[name] = true
+		if err := v.fs.RemoveAll(joinPath(p, name)); err != nil {
+			if me, ok := err.(MultiError); ok {
+				errs = append(errs, me...)
+			} else {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return v.err(errs)
+}
+
+// Mkdir is the same as FileSystem.Mkdir.
+func (v *View) Mkdir(path string) error {
+	p, err := v.path(path)
+	if err != nil {
+		return err
+	}
+	if err := v.write(path); err != nil {
+		return err
+	}
+	return v.err(v.fs.Mkdir(p))
+}
+
+// MkdirAll is the same as FileSystem.MkdirAll.
+func (v *View) MkdirAll(path string) error {
+	p, err := v.path(path)
+	if err != nil {
+		return err
+	}
+	if err := v.write(path); err != nil {
+		return err
+	}
+	return v.err(v.fs.MkdirAll(p))
+}
+
+// Copy is the same as FileSystem.Copy.
+func (v *View) Copy(srcpath, dstpath string) error {
+	src, dst, err := v.paths(srcpath, dstpath)
+	if err != nil {
+		return err
+	}
+	return v.err(v.fs.Copy(src, dst))
+}
+
+// CopyTree is the same as FileSystem.CopyTree.
+func (v *View) CopyTree(srcpath, dstpath string) error {
+	src, dst, err := v.paths(srcpath, dstpath)
+	if err != nil {
+		return err
+	}
+	return v.err(v.fs.CopyTree(src, dst))
+}
+
+// Rename is the same as FileSystem.Rename, except that the root of the View cannot be renamed (or replaced).
+func (v *View) Rename(oldpath, newpath string) error {
+	oldp, newp, err := v.paths(oldpath, newpath)
+	if err != nil {
+		return err
+	}
+	if oldp == v.prefix {
+		return &Error{Path: oldpath, Typ: ErrBadPath}
+	}
+	if newp == v.prefix {
+		return &Error{Path: newpath, Typ: ErrBadPath}
+	}
+	return v.err(v.fs.Rename(oldp, newp))
+}
+
+// paths converts the source and destination paths for Copy, CopyTree, and Rename, checking that the View is
+// writable.
+func (v *View) paths(src, dst string) (string, string, error) {
+	srcp, err := v.path(src)
+	if err != nil {
+		return "", "", err
+	}
+	dstp, err := v.path(dst)
+	if err != nil {
+		return "", "", err
+	}
+	if err := v.write(dst); err != nil {
+		return "", "", err
+	}
+	return srcp, dstp, nil
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package axis2_test
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	
+	"github.com/milochristiansen/axis2"
+	axisfs "github.com/milochristiansen/axis2/sources/iofs"
+	"github.com/milochristiansen/axis2/sources/mem"
+)
+
+func TestSub(t *testing.T) {
+	afs := new(axis2.FileSystem)
+	afs.Mount("", mem.NewDir(), true)
+	if err := afs.WriteAll("secret.txt", []byte("secret")); err != nil {
+		t.Fatal(err)
+	}
+	
+	view, err := afs.Sub("mods/base")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := view.WriteAll("a/x.txt", []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := afs.ReadAll("mods/base/a/x.txt"); string(content) != "x" {
+		t.Errorf("write through view went to the wrong place: %q", content)
+	}
+	
+	for _, path := range []string{"../secret.txt", "a/../../secret.txt", "/../secret.txt"} {
+		if _, err := view.ReadAll(path); !errors.Is(err, axis2.ErrBadPathSentinel) {
+			t.Errorf("%v: expected ErrBadPath, got: %v", path, err)
+		}
+	}
+	if view.Exists("secret.txt") {
+		t.Error("view can see outside of its prefix")
+	}
+	
+	_, err = view.ReadAll("a/missing.txt")
+	var aerr *axis2.Error
+	if !errors.As(err, &aerr) || aerr.Path != "a/missing.txt" {
+		t.Errorf("error path not relative to view: %v", err)
+	}
+	
+	matches, err := view.Glob("**/*.txt")
+	if err != nil || len(matches) != 1 || matches[0] != "a/x.txt" {
+		t.Errorf("unexpected glob result: %v, %v", matches, err)
+	}
+	var walked []string
+	view.Walk("", func(path string, info *axis2.FileInfo, err error) error {
+		walked = append(walked, path)
+		return err
+	})
+	if len(walked) != 3 || walked[0] != "" || walked[2] != "a/x.txt" {
+		t.Errorf("unexpected walk result: %q", walked)
+	}
+	
+	// Later mounts on the parent show up in the view.
+	afs.Mount("mods/base/extra", memDir(t, map[string]string{"y.txt": "y"}), false)
+	if !view.IsDir("extra") || !view.Exists("extra/y.txt") {
+		t.Error("view does not see later mount")
+	}
+	
+	ro := view.ReadOnly()
+	if _, err := ro.ReadAll("a/x.txt"); err != nil {
+		t.Error(err)
+	}
+	if err := ro.WriteAll("a/x.txt", nil); !errors.Is(err, axis2.ErrReadOnlySentinel) {
+		t.Errorf("write to read-only view: %v", err)
+	}
+	if err := ro.Delete("a/x.txt"); !errors.Is(err, axis2.ErrReadOnlySentinel) {
+		t.Errorf("delete from read-only view: %v", err)
+	}
+	if info, err := ro.Stat("a/x.txt"); err != nil || info.Mode&0222 != 0 {
+		t.Errorf("read-only view reports writable file: %v", err)
+	}
+	
+	sub, err := ro.Sub("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sub.WriteAll("x.txt", nil); !errors.Is(err, axis2.ErrReadOnlySentinel) {
+		t.Errorf("sub view of read-only view is writable: %v", err)
+	}
+	if content, _ := sub.ReadAll("x.txt"); string(content) != "x" {
+		t.Errorf("unexpected contents: %q", content)
+	}
+}
+
+func TestSubErrors(t *testing.T) {
+	afs := new(axis2.FileSystem)
+	afs.Mount("", axisfs.NewDir(deniedFS{}), false)
+	afs.Mount("mods/base/denied", axisfs.NewDir(deniedFS{}), false)
+	
+	view, err := afs.Sub("mods/base")
+	if err != nil {
+		t.Fatal(err)
+	}
+	
+	// DataSources mounted outside the view are not reported, but the error they caused is.
+	_, err = view.ReadAll("missing.txt")
+	var serr *axis2.SourceError
+	if !errors.Is(err, axis2.ErrNotFoundSentinel) || !errors.Is(err, fs.ErrPermission) || errors.As(err, &serr) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if strings.Contains(err.Error(), "mods") {
+		t.Errorf("error includes paths outside the view: %v", err)
+	}
+	
+	// DataSources mounted inside are, with paths relative to the view.
+	_, err = view.ReadAll("denied/x.txt")
+	if !errors.As(err, &serr) || serr.MountPoint != "denied" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var aerr *axis2.Error
+	if !errors.As(serr.Err, &aerr) || aerr.Path != "denied/x.txt" || strings.Contains(err.Error(), "mods") {
+		t.Errorf("unexpected SourceError: %v", serr)
+	}
+}
+
+func TestSubSources(t *testing.T) {
+	inner := mem.NewDir()
+	afs := new(axis2.FileSystem)
+	afs.Mount("plugins/foo/inner", inner, true)
+	afs.Mount("plugins", mem.NewDir(), true)
+	for _, name := range []string{"plugins/foo/a.txt", "plugins/foo/inner/b.txt", "plugins/bar/secret.txt"} {
+		if err := afs.WriteAll(name, []byte(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	
+	view, err := afs.Sub("plugins/foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ro := view.ReadOnly()
+	
+	// DataSources mounted outside the view (or anywhere, for a read-only view) are not exposed.
+	if info, err := view.Stat("a.txt"); err != nil || info.Source != nil || info.MountPoint != "" {
+		t.Errorf("Stat exposes a DataSource outside the view: %#v (%v)", info, err)
+	}
+	if info, err := view.Stat("inner/b.txt"); err != nil || info.Source != inner || info.MountPoint != "inner" {
+		t.Errorf("unexpected Stat result for a DataSource inside the view: %#v (%v)", info, err)
+	}
+	if info, err := ro.Stat("inner/b.txt"); err != nil || info.Source != nil || info.MountPoint != "" {
+		t.Errorf("read-only view exposes a DataSource: %#v (%v)", info, err)
+	}
+	for _, v := range []*axis2.View{view, ro} {
+		v.Walk("", func(path string, info *axis2.FileInfo, err error) error {
+			if info != nil && info.Source != nil && (v == ro || path == "" || path == "a.txt") {
+				t.Errorf("%v: Walk exposes a DataSource: %#v", path, info)
+			}
+			return err
+		})
+	}
+	
+	// The root of the view cannot be deleted or renamed.
+	if err := view.Delete(""); !errors.Is(err, axis2.ErrBadPathSentinel) {
+		t.Errorf("Delete of the view root: %v", err)
+	}
+	if err := view.Rename("", "x"); !errors.Is(err, axis2.ErrBadPathSentinel) {
+		t.Errorf("Rename of the view root: %v", err)
+	}
+	if err := view.Rename("a.txt", ""); !errors.Is(err, axis2.ErrBadPathSentinel) {
+		t.Errorf("Rename to the view root: %v", err)
+	}
+	
+	// RemoveAll empties it instead.
+	if err := view.RemoveAll(""); err != nil {
+		t.Fatal(err)
+	}
+	if !afs.IsDir("plugins/foo") || afs.Exists("plugins/foo/a.txt") || len(afs.List("plugins/foo/inner")) != 0 {
+		t.Errorf("view root was not emptied: %v", afs.List("plugins/foo"))
+	}
+	if !afs.Exists("plugins/bar/secret.txt") {
+		t.Error("RemoveAll of the view root removed something outside the view")
+	}
+}
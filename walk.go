@@ -0,0 +1,105 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package axis2
+
+import iofs "io/fs"
+import "sort"
+import "strings"
+
+// WalkFunc is the type of the function called by Walk for each item visited.
+// 
+// The rules are the same as for fs.WalkDirFunc: If the item could not be accessed info is nil and err describes the
+// problem. Returning fs.SkipDir skips the current directory (or the rest of the parent directory if the item is a
+// File), returning fs.SkipAll skips everything left, and returning any other error stops the walk and causes Walk to
+// return that error.
+type WalkFunc func(path string, info *FileInfo, err error) error
+
+// Walk walks the tree rooted at root, calling fn for each item (including root).
+// 
+// The merged view of every DataSource mounted for reading is walked, just like with List. Mount point subsets are
+// treated as directories, and items are always visited in lexical order no matter what order the DataSources list
+// them in. Walk does not follow any kind of link.
+func (fs *FileSystem) Walk(root string, fn WalkFunc) error {
+	dirs := validatePath(root)
+	if dirs == nil {
+		return skipOK(fn(root, nil, &Error{Path: root, Typ: ErrBadPath}))
+	}
+	root = strings.Join(dirs, "/")
+	
+	info, err := fs.Stat(root)
+	if err != nil {
+		return skipOK(fn(root, nil, err))
+	}
+	return skipOK(fs.walk(root, info, fn))
+}
+
+// WalkDir is exactly like Walk, except it uses a standard fs.WalkDirFunc.
+func (fs *FileSystem) WalkDir(root string, fn iofs.WalkDirFunc) error {
+	return fs.Walk(root, func(path string, info *FileInfo, err error) error {
+		if info == nil {
+			return fn(path, nil, err)
+		}
+		return fn(path, newIOInfo(info.Name, info), err)
+	})
+}
+
+func (fs *FileSystem) walk(path string, info *FileInfo, fn WalkFunc) error {
+	if err := fn(path, info, nil); err != nil || !info.IsDir {
+		if err == iofs.SkipDir && info.IsDir {
+			err = nil
+		}
+		return err
+	}
+	
+	names := fs.List(path)
+	sort.Strings(names)
+	for i, name := range names {
+		// List may return duplicates if mount points overlap data sources.
+		if i > 0 && names[i-1] == name {
+			continue
+		}
+		
+		cpath := joinPath(path, name)
+		cinfo, err := fs.Stat(cpath)
+		if err != nil {
+			err = fn(cpath, nil, err)
+		} else {
+			err = fs.walk(cpath, cinfo, fn)
+		}
+		if err != nil {
+			if err == iofs.SkipDir {
+				break
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// skipOK filters out the errors used to stop a walk early.
+func skipOK(err error) error {
+	if err == iofs.SkipDir || err == iofs.SkipAll {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+
+package axis2_test
+
+import (
+	"errors"
+	iofs "io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+	
+	"github.com/milochristiansen/axis2"
+	axisfs "github.com/milochristiansen/axis2/sources/iofs"
+)
+
+// ghostDir lists a "ghost.txt" that does not exist.
+type ghostDir struct {
+	axis2.Dir
+}
+
+func (d ghostDir) List() []string {
+	return append(d.Dir.List(), "ghost.txt")
+}
+
+// walkFS returns a FileSystem with two overlapping DataSources on the root and a third mounted on top of a directory
+// one of them provides.
+func walkFS() *axis2.FileSystem {
+	afs := new(axis2.FileSystem)
+	afs.Mount("", axisfs.NewDir(fstest.MapFS{
+		"a/x.txt":   {},
+		"a/y.txt":   {},
+		"a/b/z.txt": {},
+		"c.txt":     {},
+		"d/e.txt":   {},
+		"d/f/h.txt": {},
+	}), false)
+	afs.Mount("", axisfs.NewDir(fstest.MapFS{
+		"a/w.txt": {},
+		"c.txt":   {},
+		"d/e.txt": {},
+	}), false)
+	afs.Mount("d/f", axisfs.NewDir(fstest.MapFS{
+		"g.txt": {},
+	}), false)
+	return afs
+}
+
+// walkPaths walks root, returning the visited paths (separated by spaces) and the final error. If an item has a path
+// in stop the error given for it is returned for that item.
+func walkPaths(afs *axis2.FileSystem, root string, stop map[string]error) (string, error) {
+	paths := []string{}
+	err := afs.Walk(root, func(path string, info *axis2.FileInfo, err error) error {
+		if err != nil {
+			paths = append(paths, path+"!")
+			return err
+		}
+		paths = append(paths, path)
+		return stop[path]
+	})
+	return strings.Join(paths, " "), err
+}
+
+func TestWalk(t *testing.T) {
+	afs := walkFS()
+	
+	errStop := errors.New("stop")
+	tests := []struct {
+		name string
+		stop map[string]error
+		want string
+		err  error
+	}{
+		{"all", nil, " a a/b a/b/z.txt a/w.txt a/x.txt a/y.txt c.txt d d/e.txt d/f d/f/g.txt d/f/h.txt", nil},
+		{"SkipDir", map[string]error{"a": iofs.SkipDir}, " a c.txt d d/e.txt d/f d/f/g.txt d/f/h.txt", nil},
+		{"SkipDir file", map[string]error{"a/w.txt": iofs.SkipDir}, " a a/b a/b/z.txt a/w.txt c.txt d d/e.txt d/f d/f/g.txt d/f/h.txt", nil},
+		{"SkipAll", map[string]error{"a/b": iofs.SkipAll}, " a a/b", nil},
+		{"error", map[string]error{"a/w.txt": errStop}, " a a/b a/b/z.txt a/w.txt", errStop},
+		{"root SkipDir", map[string]error{"": iofs.SkipDir}, "", nil},
+	}
+	for _, test := range tests {
+		got, err := walkPaths(afs, "", test.stop)
+		if got != test.want {
+			t.Errorf("%v: unexpected paths:\n\tgot:  %q\n\twant: %q", test.name, got, test.want)
+		}
+		if err != test.err {
+			t.Errorf("%v: unexpected error: %v", test.name, err)
+		}
+	}
+	
+	if got, err := walkPaths(afs, "d", nil); got != "d d/e.txt d/f d/f/g.txt d/f/h.txt" || err != nil {
+		t.Errorf("unexpected result walking a subtree: %q (%v)", got, err)
+	}
+}
+
+func TestWalkErrors(t *testing.T) {
+	afs := new(axis2.FileSystem)
+	afs.Mount("", ghostDir{axisfs.NewDir(fstest.MapFS{
+		"a.txt": {},
+		"b.txt": {},
+	})}, false)
+	
+	// Items that cannot be accessed are passed to fn with the error, which stops the walk if fn returns it.
+	got, err := walkPaths(afs, "", nil)
+	if got != " a.txt b.txt ghost.txt!" || !errors.Is(err, axis2.ErrNotFoundSentinel) {
+		t.Errorf("unexpected result: %q (%v)", got, err)
+	}
+	
+	// Otherwise the walk goes on.
+	var visited []string
+	err = afs.Walk("", func(path string, info *axis2.FileInfo, err error) error {
+		if err != nil && info != nil {
+			t.Errorf("%v: info is not nil for an error", path)
+		}
+		visited = append(visited, path)
+		return nil
+	})
+	if err != nil || len(visited) != 4 {
+		t.Errorf("unexpected result ignoring errors: %q (%v)", visited, err)
+	}
+	
+	if got, err := walkPaths(afs, "missing", nil); got != "missing!" || !errors.Is(err, axis2.ErrNotFoundSentinel) {
+		t.Errorf("unexpected result for a missing root: %q (%v)", got, err)
+	}
+	if got, err := walkPaths(afs, "../x", nil); got != "../x!" || !errors.Is(err, axis2.ErrBadPathSentinel) {
+		t.Errorf("unexpected result for a bad root: %q (%v)", got, err)
+	}
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2016 by Milo Christiansen
+
+This software is provided 'as-is', without any express or implied warranty. In
+no event will the authors be held liable for any damages arising from the use of
+this software.
+
+Permission is granted to anyone to use this software for any purpose, including
+commercial applications, and to alter it and redistribute it freely, subject to
+the following restrictions:
+
+1. The origin of this software must not be misrepresented; you must not claim
+that you wrote the original software. If you use this software in a product, an
+acknowledgment in the product documentation would be appreciated but is not
+required.
+
+2. Altered source versions must be plainly marked as such, and must not be
+misrepresented as being the original software.
+
+3. This notice may not be removed or altered from any source distribution.
+*/
+
+package axis2
+
+import "strings"
+
+// Whiteout markers, as used by overlay file systems and container images.
+const (
+	// An empty File named WhiteoutPrefix followed by the name of an item hides that item.
+	WhiteoutPrefix = ".wh."
+	
+	// A Dir that contains an empty File with this name hides everything in the same Dir.
+	OpaqueMarker = WhiteoutPrefix + WhiteoutPrefix + ".opq"
+)
+
+// Whiteouts turns whiteout markers on or off. Whiteouts are off by default.
+// 
+// When whiteouts are on, a whiteout marker hides an item (or an opaque marker hides the contents of a Dir) from every
+// DataSource after the one containing the marker on the read half. Markers never hide anything in the DataSource they
+// are in, and they are never visible themselves.
+// 
+// This allows "deleting" items that are on the read half only. Delete and RemoveAll add a whiteout marker to the first
+// suitable DataSource on the write half if the item can still be found after it is removed from the write half. For
+// this to work the DataSource must also be mounted for reading, in front of the DataSource(s) the item comes from.
+// 
+// A marker also hides any DataSources mounted inside the item it hides, as long as they come after the marker on the
+// read half.
+func (fs *FileSystem) Whiteouts(on bool) {
+	fs.update(func(t *mountTable) {
+		t.whiteouts = on
+	})
+}
+
+// isMarkerPath returns true if the path goes through a whiteout marker.
+func isMarkerPath(dirs []string) bool {
+	for _, dir := range dirs {
+		if strings.HasPrefix(dir, WhiteoutPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// whiteouts keeps track of what is hidden while listing the same Dir in several DataSources.
+type whiteouts struct {
+	hidden map[string]bool
+	opaque bool
+}
+
+// filter removes the markers and any hidden items from the list of names from a Dir, then adds that Dir's markers
+// to the set of hidden items. Dirs must be filtered in the same order they appear in the read half.
+func (w *whiteouts) filter(names []string) []string {
+	if w.opaque {
+		return nil
+	}
+	
+	var markers []string
+	rtn := make([]string, 0, len(names))
+	for _, name := range names {
+		switch {
+		case name == OpaqueMarker:
+			w.opaque = true
+		case strings.HasPrefix(name, WhiteoutPrefix):
+			markers = append(markers, strings.TrimPrefix(name, WhiteoutPrefix))
+		case !w.hidden[name]:
+			rtn = append(rtn, name)
+		}
+	}
+	
+	if w.hidden == nil {
+		w.hidden = map[string]bool{}
+	}
+	for _, name := range markers {
+		w.hidden[name] = true
+	}
+	return rtn
+}
+
+// whiteout hides the item at the given path on the read half, if it can still be found there. Returns true if a
+// marker was added.
+// 
+// The marker is added to the first DataSource on the write half that is also on the read half, in front of the first
+// DataSource that still contains the item. If opaque is true, and the path is the mount point of such a DataSource, an
+// opaque marker is added to it instead (so the path still exists, but appears empty). In this case DataSources on the
+// write half that are mounted at the path are assumed to be empty, and are ignored.
+func (fs *FileSystem) whiteout(dirs []string, opaque bool) (bool, error) {
+	t := fs.table.Load()
+	if t == nil {
+		return false, nil
+	}
+	var matches []match
+	for _, m := range fs.lookup(dirs, CreateNone, true) {
+		if opaque && len(m.src.mp) == len(dirs) && indexOf(m.src, t.w) != -1 {
+			continue
+		}
+		matches = append(matches, m)
+	}
+	if len(matches) == 0 {
+		return false, nil
+	}
+	path := strings.Join(dirs, "/")
+	
+	for _, src := range t.w {
+		if n := indexOf(src, t.r); n == -1 || n >= matches[0].n {
+			continue
+		}
+		
+		var marker []string
+		switch {
+		case len(dirs) > 0 && hasPrefix(dirs[:len(dirs)-1], src.mp):
+			marker = append(append([]string{}, dirs[:len(dirs)-1]...), WhiteoutPrefix+dirs[len(dirs)-1])
+		case opaque && hasPrefix(dirs, src.mp):
+			marker = append(append([]string{}, dirs...), OpaqueMarker)
+		default:
+			continue
+		}
+		
+		ds, _ := fs.follow(t, src, marker, CreateFile, false, nil)
+		f, ok := ds.(File)
+		if !ok {
+			continue
+		}
+		w, err := f.Write()
+		if err != nil {
+			return false, wrapError(err, path)
+		}
+		return true, wrapError(w.Close(), path)
+	}
+	return false, &Error{Path: path, Typ: ErrReadOnly}
+}
+
+// visibleSubset is mountSubset for the read half, except that if whiteouts are on mount points are left out when every
+// DataSource mounted on or inside them is hidden by a marker.
+func (fs *FileSystem) visibleSubset(path string) []string {
+	names := fs.mountSubset(path, true)
+	t := fs.table.Load()
+	if t == nil || !t.whiteouts || len(names) == 0 {
+		return names
+	}
+	
+	dirs := validatePath(path)
+	rtn := names[:0]
+	for _, name := range names {
+		if !fs.mountHidden(t, append(dirs[:len(dirs):len(dirs)], name)) {
+			rtn = append(rtn, name)
+		}
+	}
+	return rtn
+}
+
+// mountHidden returns true if every DataSource on the read half that is mounted on or inside the given path is hidden
+// by a marker in a DataSource before it.
+func (fs *FileSystem) mountHidden(t *mountTable, dirs []string) bool {
+	next:
+	for n, src := range t.r {
+		if !hasPrefix(src.mp, dirs) {
+			continue
+		}
+		for _, o := range t.r[:n] {
+			if !hasPrefix(src.mp, o.mp) {
+				continue
+			}
+			if _, hidden := fs.follow(t, o, src.mp, CreateNone, true, nil); hidden {
+				continue next
+			}
+		}
+		return false
+	}
+	return true
+}